@@ -29,7 +29,7 @@ var (
 func main() {
 	flag.Usage = func() {
 		u := " Usage:\n"
-		u += "  rp-client [OPTIONS] (DIR|FILE)\n\n"
+		u += "  rp-client [OPTIONS] (DIR|FILE|-)\n\n"
 		u += " Options:\n"
 		u += "	-r	--rp		Report Portal host\n"
 		u += "	-d	--debug		Report Portal debug mode\n"
@@ -86,7 +86,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	if _, err := os.Stat(reportDir); os.IsNotExist(err) {
+	if _, err := os.Stat(reportDir); reportDir != "-" && os.IsNotExist(err) {
 		fmt.Printf("invalid tests results directory path '%s'\n", reportDir)
 		flag.Usage()
 		os.Exit(1)
@@ -110,7 +110,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	report, err := rp.LoadXMLReport(reportDir)
+	var report *rp.XMLReport
+	var err error
+	if reportDir == "-" {
+		report, err = rp.LoadXMLReportStdin()
+	} else {
+		report, err = rp.LoadXMLReport(reportDir)
+	}
 
 	if err != nil {
 		fmt.Printf("could not load report")
@@ -138,50 +144,16 @@ func main() {
 
 	rpClient := rp.NewClient(hostFlag, projectFlag, uuidFlag)
 
-	launchID := rpClient.StartLaunch(launch)
-	if launchID == nil {
-		fmt.Println("could not start launch")
+	launchEnd := report.LaunchEndTime()
+	fmt.Printf("launch end: %s\n", launchEnd.Format(rp.TimestampLayout))
+	finishResult, err := rpClient.Publish(report, launch)
+	if err != nil {
+		fmt.Printf("could not publish report: %v\n", err)
 		os.Exit(1)
 	}
-
-	for i := 0; i < report.SuitesCount(); i++ {
-		suite := report.Suite(i)
-		suite.LaunchID = launchID.ID
-		suiteID := rpClient.StartTestItem("", suite)
-
-		for j := 0; j < report.TesCaseCount(i); j++ {
-			tCase := report.TestCase(i, j)
-			tCase.LaunchID = launchID.ID
-			tCaseID := rpClient.StartTestItem(suiteID.ID, tCase)
-
-			if report.HasTestCaseFailure(i, j) {
-				tFailure := report.TestCaseFailure(i, j)
-				tFailure.ItemID = tCaseID.ID
-				rpClient.SendMesssage(tFailure)
-				fDetails := report.TestCaseFailureDetails(i, j)
-				fDetails.ItemID = tCaseID.ID
-				rpClient.SendMesssage(fDetails)
-			}
-
-			if report.HasTestCaseSkipped(i, j) {
-				sMessage := report.TesCaseSkippedMessage(i, j)
-				sMessage.ItemID = tCaseID.ID
-				rpClient.SendMesssage(sMessage)
-			}
-
-			tResult := report.TestCaseResult(i, j)
-			rpClient.FinishTestItem(tCaseID.ID, tResult)
-		}
-
-		suiteResult := report.SuiteResult(i)
-		rpClient.FinishTestItem(suiteID.ID, suiteResult)
+	if len(finishResult.Link) != 0 {
+		fmt.Printf("launch link: %s\n", finishResult.Link)
 	}
 
-	launchEnd := report.LaunchEndTime()
-	fmt.Printf("launch end: %s\n", launchEnd.Format(rp.TimestampLayout))
-	rpClient.FinishLaunch(launchID.ID, &rp.ExecutionResult{
-		EndTime: launchEnd,
-	})
-
 	os.Exit(0)
 }
@@ -0,0 +1,28 @@
+package rp
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// backoff calculates delay before request retry
+type backoff struct {
+	base   time.Duration
+	max    time.Duration
+	factor float64
+	jitter bool
+}
+
+// delay before given retry (starting from 1): base delay multiplied by factor for every previous retry
+// and capped by max, with jitter the delay is randomized in range [0, delay)
+func (b backoff) delay(retry int) time.Duration {
+	d := float64(b.base) * math.Pow(b.factor, float64(retry-1))
+	if b.max > 0 && d > float64(b.max) {
+		d = float64(b.max)
+	}
+	if b.jitter {
+		d = rand.Float64() * d
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,46 @@
+package rp
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRetryOptionsOrder(t *testing.T) {
+	retry := WithRetry(3, time.Second)
+	strategy := WithBackoffStrategy(10*time.Millisecond, time.Second, 2, false)
+	for _, opts := range [][]ClientOption{{retry, strategy}, {strategy, retry}} {
+		c := NewClient("http://localhost", "project", "uuid", opts...)
+		if c.retryAttempts != 3 {
+			t.Errorf("expected 3 retry attempts, got %d", c.retryAttempts)
+		}
+		if c.retryBackoff == nil {
+			t.Fatal("expected backoff strategy to be kept")
+		}
+		for retry, want := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond} {
+			if d := c.retryBackoff.delay(retry + 1); d != want {
+				t.Errorf("retry %d: expected delay %s, got %s", retry+1, want, d)
+			}
+		}
+	}
+}
+
+func TestBackoffStrategyRejectsFactorBelowOne(t *testing.T) {
+	for _, factor := range []float64{0, 0.5, -2, math.NaN()} {
+		c := NewClient("http://localhost", "project", "uuid",
+			WithRetry(2, time.Second), WithBackoffStrategy(time.Millisecond, time.Second, factor, false))
+		if c.retryBackoff != nil {
+			t.Errorf("factor %v: expected backoff strategy to be rejected", factor)
+		}
+		if c.retryDelay != time.Second {
+			t.Errorf("factor %v: expected fixed retry delay to be kept, got %s", factor, c.retryDelay)
+		}
+	}
+}
+
+func TestBackoffDelayCapped(t *testing.T) {
+	b := backoff{base: time.Second, max: 3 * time.Second, factor: 2}
+	if d := b.delay(5); d != 3*time.Second {
+		t.Errorf("expected delay capped to 3s, got %s", d)
+	}
+}
@@ -0,0 +1,60 @@
+package rp
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestClientConcurrentUse is meant to be run with -race
+func TestClientConcurrentUse(t *testing.T) {
+	rp := newFakeRP(t)
+	c := rp.client(WithLogLimit(5, 5), WithRedactor(RedactSecrets))
+	launchID := c.StartLaunch(&Launch{Name: "concurrent"})
+	if launchID == nil {
+		t.Fatal("could not start launch")
+	}
+	w := c.LogWriter("shared", LogLevelInfo)
+
+	const workers, logs = 16, 25
+	var wg sync.WaitGroup
+	for k := 0; k < workers; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			item := &TestItem{LaunchID: launchID.ID, Name: fmt.Sprint("case ", k), Type: TestItemTypeStep, Ordinal: k + 1}
+			itemID := c.StartTestItem("", item)
+			if itemID == nil {
+				t.Error("could not start test item")
+				return
+			}
+			for n := 0; n < logs; n++ {
+				err := c.SendLogs([]*LogMessage{{ItemID: itemID.ID, Level: LogLevelInfo, Message: fmt.Sprint("log ", n)}})
+				if err != nil {
+					t.Error(err)
+				}
+				fmt.Fprintf(w, "worker %d line %d\n", k, n)
+			}
+			if err := c.FinishTestItem(itemID.ID, &ExecutionResult{Status: ExecutionStatusPassed}); err != nil {
+				t.Error(err)
+			}
+		}(k)
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// log limit sends first and last 5 logs and omission marker of every finished item,
+	// only first 5 writer lines are sent as shared item is never finished
+	perItem := 5 + 5 + 1
+	if got, want := len(rp.logs), workers*perItem+5; got != want {
+		t.Errorf("expected %d logs, got %d", want, got)
+	}
+	if ids := c.unfinished.unfinished(""); len(ids) != 0 {
+		t.Errorf("expected nothing left unfinished, got %v", ids)
+	}
+}
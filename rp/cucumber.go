@@ -0,0 +1,132 @@
+package rp
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// cucumberFeature identifies Cucumber JSON report feature
+type cucumberFeature struct {
+	URI      string            `json:"uri"`
+	Name     string            `json:"name"`
+	Elements []cucumberElement `json:"elements"`
+}
+
+type cucumberElement struct {
+	Name  string         `json:"name"`
+	Type  string         `json:"type"`
+	Tags  []cucumberTag  `json:"tags"`
+	Steps []cucumberStep `json:"steps"`
+}
+
+type cucumberTag struct {
+	Name string `json:"name"`
+}
+
+type cucumberStep struct {
+	Keyword string         `json:"keyword"`
+	Name    string         `json:"name"`
+	Result  cucumberResult `json:"result"`
+}
+
+type cucumberResult struct {
+	Status       string `json:"status"`
+	Duration     int64  `json:"duration"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// LoadCucumberReport is used for loading Cucumber JSON report: features become suites and scenarios become test cases.
+// Scenario with failed step is failed, with skipped, pending or undefined step is skipped, failed step error is failure message.
+// Background steps are folded into the following scenario, so failed background step makes the scenario failed.
+// Scenario tags become test case tags. Cucumber has no timestamps, so suites start at load time
+func LoadCucumberReport(r io.Reader, opts ...ReportOption) (*XMLReport, error) {
+	var features []cucumberFeature
+	err := json.NewDecoder(r).Decode(&features)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now().UTC()
+	xSuites := make([]xmlSuite, 0, len(features))
+	for _, feature := range features {
+		xSuite := cucumberSuite(feature, start)
+		start = start.Add(xSuite.Time.duration())
+		xSuites = append(xSuites, xSuite)
+	}
+
+	report := newXMLReport(opts)
+	report.setSuites(xSuites)
+	return report, nil
+}
+
+// cucumberSuite maps feature started at given time to suite
+func cucumberSuite(feature cucumberFeature, start time.Time) xmlSuite {
+	xSuite := xmlSuite{
+		Name:      feature.Name,
+		TimeStamp: start.Format(TimestampLayout),
+		Cases:     make([]xmlTest, 0, len(feature.Elements)),
+	}
+	// background element is written before every scenario it is run for
+	var background []cucumberStep
+	for _, element := range feature.Elements {
+		if element.Type == "background" {
+			background = append(background, element.Steps...)
+			continue
+		}
+		if len(background) != 0 {
+			element.Steps = append(background, element.Steps...)
+			background = nil
+		}
+		xCase := cucumberCase(element)
+		xCase.ordinal = len(xSuite.Cases)
+		if xCase.Failure != nil {
+			xSuite.Failures++
+		}
+		if xCase.Skipped != nil {
+			xSuite.Skipped++
+		}
+		xSuite.Tests++
+		xSuite.Time += xCase.Time
+		xSuite.Cases = append(xSuite.Cases, xCase)
+	}
+	return xSuite
+}
+
+// cucumberCase maps scenario to test case with status derived from step results
+func cucumberCase(element cucumberElement) xmlTest {
+	xCase := xmlTest{
+		Name: element.Name,
+	}
+	for _, tag := range element.Tags {
+		xCase.tags = append(xCase.tags, strings.TrimPrefix(tag.Name, "@"))
+	}
+
+	var duration time.Duration
+	for _, step := range element.Steps {
+		duration += time.Duration(step.Result.Duration)
+		switch step.Result.Status {
+		case "failed":
+			if xCase.Failure == nil {
+				errorLines := strings.SplitN(step.Result.ErrorMessage, "\n", 2)
+				xCase.Failure = &xmlFailure{
+					Message: strings.TrimSpace(step.Keyword) + " " + step.Name + ": " + errorLines[0],
+					Details: step.Result.ErrorMessage,
+				}
+			}
+		case "skipped", "pending", "undefined":
+			if xCase.Skipped == nil {
+				xCase.Skipped = &xmlSkipped{
+					Message: strings.TrimSpace(step.Keyword) + " " + step.Name + ": " + step.Result.Status,
+				}
+			}
+		}
+	}
+	// failed step makes the scenario failed even when later steps are skipped
+	if xCase.Failure != nil {
+		xCase.Skipped = nil
+	}
+	xCase.Time = xmlSeconds(duration.Seconds())
+	return xCase
+}
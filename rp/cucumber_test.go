@@ -0,0 +1,36 @@
+package rp
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadCucumberReportBackground(t *testing.T) {
+	f, err := os.Open("testdata/cucumber/background.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	report, err := LoadCucumberReport(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := report.TesCaseCount(0); n != 2 {
+		t.Fatalf("expected 2 scenarios, got %d", n)
+	}
+
+	if status := report.TestCaseResult(0, 0).Status; status != ExecutionStatusFailed {
+		t.Errorf("expected scenario with failed background step to be failed, got %s", status)
+	}
+	want := "Given the database is seeded: connection refused"
+	if message := report.TestCaseFailure(0, 0).Message; message != want {
+		t.Errorf("expected failure message %q, got %q", want, message)
+	}
+	if status := report.TestCaseResult(0, 1).Status; status != ExecutionStatusPassed {
+		t.Errorf("expected scenario with passed background to be passed, got %s", status)
+	}
+	if d := report.TestCaseEndTime(0, 1).Sub(report.TestCaseStartTime(0, 1)); d != 3*time.Millisecond {
+		t.Errorf("expected scenario duration to include background steps, got %s", d)
+	}
+}
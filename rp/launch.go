@@ -2,46 +2,123 @@ package rp
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 )
 
-// StartLaunch creates new launch
+// StartLaunch creates new launch, empty start time is set to current client clock time
 func (c *Client) StartLaunch(launch *Launch) (launchID *ResponceID) {
-	resp, err := c.post("/launch", launch)
-	defer resp.Body.Close()
+	if launch.StartTime.IsZero() {
+		launch.StartTime = c.clock.Now()
+	}
 
+	resp, err := c.post("/launch", launch)
 	if err != nil {
 		log.Error(err)
 		return
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusCreated {
 		err := json.NewDecoder(resp.Body).Decode(&launchID)
 		if err != nil {
 			log.Error(err)
+		} else {
+			c.unfinished.start(launchID.ID, launchID.ID, true)
 		}
 	} else {
-		log.Error(decodeError(resp.Body))
+		log.Error(decodeError(resp))
 	}
 	return
 }
 
-// FinishLaunch update specified launch to passed (completed state)
-func (c *Client) FinishLaunch(launchID string, result *ExecutionResult) {
+// FinishLaunch update specified launch to passed (completed state), empty end time is set to current client clock time.
+// Returns finished launch id, number and link to view it as responded by Report Portal
+func (c *Client) FinishLaunch(launchID string, result *ExecutionResult) (*FinishResult, error) {
 	if len(launchID) == 0 {
-		log.Error("launchID could not be empty")
-		return
+		return nil, errors.New("launchID could not be empty")
+	}
+	if result.EndTime.IsZero() {
+		result.EndTime = c.clock.Now()
 	}
 
 	resp, err := c.put("/launch/"+launchID+"/finish", result)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeError(resp)
+	}
+	c.unfinished.finish(launchID)
+	if c.ordinals != nil {
+		c.ordinals.finish(ordinalParentKey("", launchID))
+	}
+
+	var finishResult FinishResult
+	err = json.NewDecoder(resp.Body).Decode(&finishResult)
 	if err != nil {
-		log.Error(err)
-		return
+		return nil, err
+	}
+	return &finishResult, nil
+}
+
+// GetLaunch provides launch by its uuid, e.g. to get sequential launch number of finished launch
+func (c *Client) GetLaunch(uuid string) (Launch, error) {
+	if len(uuid) == 0 {
+		return Launch{}, errors.New("uuid could not be empty")
+	}
+
+	resp, err := c.get("/launch/uuid/" + uuid)
+	if err != nil {
+		return Launch{}, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		log.Error(decodeError(resp.Body))
+		return Launch{}, decodeError(resp)
+	}
+
+	var launch struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Mode        Mode            `json:"mode"`
+		StartTime   json.RawMessage `json:"start_time"`
+		Tags        []string        `json:"tags"`
+		Number      int             `json:"number"`
 	}
+	err = json.NewDecoder(resp.Body).Decode(&launch)
+	if err != nil {
+		return Launch{}, err
+	}
+	return Launch{
+		Name:        launch.Name,
+		Description: launch.Description,
+		Mode:        launch.Mode,
+		StartTime:   parseResponceTime(launch.StartTime),
+		Tags:        launch.Tags,
+		Number:      launch.Number,
+	}, nil
+}
+
+// parseResponceTime parses time responded by Report Portal either as epoch milliseconds or as timestamp string
+func parseResponceTime(raw json.RawMessage) time.Time {
+	var millis int64
+	if json.Unmarshal(raw, &millis) == nil {
+		return time.Unix(0, millis*int64(time.Millisecond)).UTC()
+	}
+	var timeStr string
+	if json.Unmarshal(raw, &timeStr) == nil && len(timeStr) != 0 {
+		return parseTimeStamp(timeStr)
+	}
+	return time.Time{}
+}
+
+// ReportPortalLink provides Report Portal UI link to launch, e.g. for launch id returned by FinishLaunch,
+// baseURL is Report Portal UI address with or without trailing slash
+func ReportPortalLink(baseURL, project, launchID string) string {
+	return strings.TrimRight(baseURL, "/") + "/ui/#" + project + "/launches/all/" + launchID
 }
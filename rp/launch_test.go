@@ -0,0 +1,63 @@
+package rp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// jsonServer provides server responding to every request with given status and json body
+func jsonServer(t *testing.T, statusCode int, body string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		w.WriteHeader(statusCode)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFinishLaunchResult(t *testing.T) {
+	link := "http://rp.example.com/ui/#project/launches/all/launch"
+	server := jsonServer(t, http.StatusOK, `{"id":"launch","number":42,"link":"`+link+`"}`)
+	c := NewClient(server.URL, "project", "uuid")
+	finishResult, err := c.FinishLaunch("launch", &ExecutionResult{Status: ExecutionStatusPassed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (FinishResult{ID: "launch", Number: 42, Link: link}); *finishResult != want {
+		t.Errorf("expected finish result %+v, got %+v", want, *finishResult)
+	}
+}
+
+func TestGetLaunch(t *testing.T) {
+	start := time.Date(2017, 5, 5, 20, 3, 50, 0, time.UTC)
+	for _, startTime := range []string{"1494014630000", `"2017-05-05T20:03:50.000Z"`} {
+		server, requests := recordServer(t, http.StatusOK,
+			`{"id":"launch","name":"nightly","mode":"DEFAULT","tags":["ci"],"number":42,"start_time":`+startTime+`}`)
+		c := NewClient(server.URL, "project", "uuid")
+		launch, err := c.GetLaunch("launch")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := Launch{Name: "nightly", Mode: ModeDefault, StartTime: start, Tags: []string{"ci"}, Number: 42}
+		if !reflect.DeepEqual(launch, want) {
+			t.Errorf("start time %s: expected launch %+v, got %+v", startTime, want, launch)
+		}
+		if got := requests(); len(got) != 1 || got[0].Method != http.MethodGet || got[0].Path != "/project/launch/uuid/launch" {
+			t.Errorf("expected GET of launch by uuid, got %+v", got)
+		}
+	}
+}
+
+func TestReportPortalLink(t *testing.T) {
+	want := "http://rp.example.com:8080/ui/#project/launches/all/launch"
+	for _, baseURL := range []string{"http://rp.example.com:8080", "http://rp.example.com:8080/"} {
+		if link := ReportPortalLink(baseURL, "project", "launch"); link != want {
+			t.Errorf("base url %s: expected link %s, got %s", baseURL, want, link)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package rp
+
+import (
+	"context"
+	"io"
+)
+
+// Loader holds report options configured once and reused for loading many reports, e.g. by long running process.
+// Loader is safe for concurrent use as long as its options are
+type Loader struct {
+	opts []ReportOption
+}
+
+// NewLoader creates Loader applying given report options to every loaded report
+func NewLoader(opts ...ReportOption) *Loader {
+	return &Loader{
+		opts: append([]ReportOption(nil), opts...),
+	}
+}
+
+// Load is used for loading JUnit XML report from specified directory as by LoadXMLReport
+func (l *Loader) Load(dirName string) (*XMLReport, error) {
+	return LoadXMLReportContext(context.Background(), dirName, l.opts...)
+}
+
+// LoadContext is used for loading JUnit XML report from specified directory as by LoadXMLReportContext
+func (l *Loader) LoadContext(ctx context.Context, dirName string) (*XMLReport, error) {
+	return LoadXMLReportContext(ctx, dirName, l.opts...)
+}
+
+// Decode is used for decoding report from single <testsuite> or <testsuites> document as by DecodeSuite
+func (l *Loader) Decode(r io.Reader) (*XMLReport, error) {
+	return DecodeSuite(r, l.opts...)
+}
@@ -0,0 +1,34 @@
+package rp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoaderReusesOptions(t *testing.T) {
+	opts := []ReportOption{WithSuiteNameSuffix(" (linux)")}
+	loader := NewLoader(opts...)
+	// options of caller slice changed after creating loader are not applied
+	opts[0] = WithSuiteNameSuffix(" (windows)")
+
+	for dir, want := range map[string]string{
+		"testdata/smoke":  "s.Checkout (linux)",
+		"testdata/groups": "g.Tagged (linux)",
+	} {
+		report, err := loader.Load(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := suiteNames(report); got != want {
+			t.Errorf("%s: expected suites %q, got %q", dir, want, got)
+		}
+	}
+
+	report, err := loader.Decode(strings.NewReader(readTestFile(t, "testdata/smoke/cases.xml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "s.Checkout (linux)"; got != want {
+		t.Errorf("expected decoded suites %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,84 @@
+package rp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// logLimiter caps log messages count per test item: first messages are sent as usual,
+// only last ones of the rest are kept and sent on item finish after omission marker
+type logLimiter struct {
+	mu    sync.Mutex
+	first int
+	last  int
+	items map[string]*itemLogs
+}
+
+// itemLogs holds log counters and kept last log messages of single test item
+type itemLogs struct {
+	sent    int
+	omitted int
+	// omittedTime is time of last omitted log message
+	omittedTime time.Time
+	tail        []*LogMessage
+}
+
+func newLogLimiter(first, last int) *logLimiter {
+	return &logLimiter{
+		first: first,
+		last:  last,
+		items: make(map[string]*itemLogs),
+	}
+}
+
+// admit provides log messages which should be sent right away, the rest are kept or omitted
+func (l *logLimiter) admit(logMessages []*LogMessage) []*LogMessage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	admitted := make([]*LogMessage, 0, len(logMessages))
+	for _, logMessage := range logMessages {
+		item, ok := l.items[logMessage.ItemID]
+		if !ok {
+			item = &itemLogs{}
+			l.items[logMessage.ItemID] = item
+		}
+		if item.sent < l.first {
+			item.sent++
+			admitted = append(admitted, logMessage)
+			continue
+		}
+
+		item.tail = append(item.tail, logMessage)
+		if len(item.tail) > l.last {
+			item.omittedTime = item.tail[0].Time
+			item.tail = item.tail[1:]
+			item.omitted++
+		}
+	}
+	return admitted
+}
+
+// drain provides omission marker followed by kept last log messages of finished test item
+func (l *logLimiter) drain(itemID string) []*LogMessage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	item, ok := l.items[itemID]
+	if !ok {
+		return nil
+	}
+	delete(l.items, itemID)
+
+	logMessages := make([]*LogMessage, 0, len(item.tail)+1)
+	if item.omitted > 0 {
+		logMessages = append(logMessages, &LogMessage{
+			ItemID:  itemID,
+			Time:    item.omittedTime,
+			Level:   LogLevelWarn,
+			Message: fmt.Sprintf("... [%d lines omitted]", item.omitted),
+		})
+	}
+	return append(logMessages, item.tail...)
+}
@@ -0,0 +1,43 @@
+package rp
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestLogLimit(t *testing.T) {
+	rp := newFakeRP(t)
+	c := rp.client(WithLogLimit(2, 2))
+	var logMessages []*LogMessage
+	for k := 0; k < 7; k++ {
+		logMessages = append(logMessages, &LogMessage{ItemID: "limited", Level: LogLevelInfo, Message: fmt.Sprint(k)})
+	}
+	logMessages = append(logMessages, &LogMessage{ItemID: "other", Level: LogLevelInfo, Message: "other"})
+	if err := c.SendLogs(logMessages); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.FinishTestItem("limited", &ExecutionResult{Status: ExecutionStatusPassed}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, logMessage := range rp.logs {
+		got = append(got, logMessage.ItemID+": "+logMessage.Message)
+	}
+	// last log messages are sent on finish after omission marker
+	want := []string{
+		"limited: 0",
+		"limited: 1",
+		"other: other",
+		"limited: ... [3 lines omitted]",
+		"limited: 5",
+		"limited: 6",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected logs %q, got %q", want, got)
+	}
+	if status, _ := rp.finishedStatus("limited"); status != string(ExecutionStatusPassed) {
+		t.Errorf("expected item to be finished after its last logs, got %q", status)
+	}
+}
@@ -0,0 +1,105 @@
+package rp
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// logBatchSize is count of log messages sent to Report Portal in single batch request
+const logBatchSize = 20
+
+// logWriter splits written text into lines and sends each line as log message of test item,
+// it is safe for concurrent use
+type logWriter struct {
+	mu      sync.Mutex
+	client  *Client
+	itemID  string
+	level   LogLevel
+	line    bytes.Buffer
+	pending []*LogMessage
+}
+
+// SendLogBatches sends log messages received from channel in fixed size batches as soon as batch is full,
+// so memory stays bounded regardless of total log volume. It is a batcher rather than a streamed upload: every batch
+// is sent as separate SendLogs request without chunked transfer encoding, since client requests are buffered to be
+// retried, compressed and logged. Channel is drained until closed and first error is returned
+func (c *Client) SendLogBatches(logMessages <-chan *LogMessage) error {
+	var err error
+	batch := make([]*LogMessage, 0, logBatchSize)
+	send := func() {
+		sendErr := c.SendLogs(batch)
+		if sendErr != nil && err == nil {
+			err = sendErr
+		}
+		batch = batch[:0]
+	}
+
+	for logMessage := range logMessages {
+		batch = append(batch, logMessage)
+		if len(batch) == logBatchSize {
+			send()
+		}
+	}
+	send()
+	return err
+}
+
+// LogWriter creates writer for test item logs, every written line becomes log message with specified level.
+// Log messages are sent in batches, Close sends remaining ones
+func (c *Client) LogWriter(itemID string, level LogLevel) io.WriteCloser {
+	return &logWriter{
+		client: c,
+		itemID: itemID,
+		level:  level,
+	}
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.line.Write(p)
+	for {
+		line, err := w.line.ReadString('\n')
+		if err != nil {
+			// keep incomplete line until next write
+			w.line.WriteString(line)
+			break
+		}
+		w.add(line)
+	}
+
+	if len(w.pending) >= logBatchSize {
+		return len(p), w.flush()
+	}
+	return len(p), nil
+}
+
+// Close sends incomplete line and all pending log messages
+func (w *logWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.line.Len() > 0 {
+		w.add(w.line.String())
+		w.line.Reset()
+	}
+	return w.flush()
+}
+
+// add new log message for line
+func (w *logWriter) add(line string) {
+	w.pending = append(w.pending, &LogMessage{
+		ItemID:  w.itemID,
+		Time:    w.client.clock.Now(),
+		Level:   w.level,
+		Message: strings.TrimRight(line, "\r\n"),
+	})
+}
+
+// flush sends pending log messages in single batch
+func (w *logWriter) flush() error {
+	pending := w.pending
+	w.pending = nil
+	return w.client.SendLogs(pending)
+}
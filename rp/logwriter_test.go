@@ -0,0 +1,97 @@
+package rp
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogWriterBatches(t *testing.T) {
+	rp := newFakeRP(t)
+	w := rp.client().LogWriter("item", LogLevelWarn)
+	for k := 1; k <= logBatchSize; k++ {
+		fmt.Fprintf(w, "line %d\n", k)
+	}
+	// incomplete line is kept until close
+	fmt.Fprint(w, "last ")
+	fmt.Fprint(w, "line\r\n")
+	fmt.Fprint(w, "tail")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rp.batches) != 2 {
+		t.Fatalf("expected 2 log batches, got %d", len(rp.batches))
+	}
+	if n := len(rp.batches[0]); n != logBatchSize {
+		t.Errorf("expected full first batch of %d logs, got %d", logBatchSize, n)
+	}
+	want := []fakeLog{
+		{ItemID: "item", Message: "last line", Level: string(LogLevelWarn)},
+		{ItemID: "item", Message: "tail", Level: string(LogLevelWarn)},
+	}
+	if !reflect.DeepEqual(rp.batches[1], want) {
+		t.Errorf("expected remaining batch %+v, got %+v", want, rp.batches[1])
+	}
+	for k, logMessage := range rp.batches[0] {
+		if want := fmt.Sprintf("line %d", k+1); logMessage.Message != want {
+			t.Errorf("log %d: expected message %q, got %q", k, want, logMessage.Message)
+		}
+	}
+}
+
+func TestSendLogBatches(t *testing.T) {
+	rp := newFakeRP(t)
+	logMessages := make(chan *LogMessage)
+	go func() {
+		for k := 0; k < 2*logBatchSize+1; k++ {
+			logMessages <- &LogMessage{ItemID: "item", Level: LogLevelInfo, Message: fmt.Sprint(k)}
+		}
+		close(logMessages)
+	}()
+	if err := rp.client().SendLogBatches(logMessages); err != nil {
+		t.Fatal(err)
+	}
+
+	var sizes []int
+	for _, batch := range rp.batches {
+		sizes = append(sizes, len(batch))
+	}
+	if want := []int{logBatchSize, logBatchSize, 1}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("expected batch sizes %v, got %v", want, sizes)
+	}
+}
+
+func TestSendLogBatchesFlushesBeforeClose(t *testing.T) {
+	rp := newFakeRP(t)
+	batches := func() int {
+		rp.mu.Lock()
+		defer rp.mu.Unlock()
+		return len(rp.batches)
+	}
+	logMessages := make(chan *LogMessage)
+	flushed := make(chan bool, 1)
+	go func() {
+		defer close(logMessages)
+		for k := 0; k < logBatchSize; k++ {
+			logMessages <- &LogMessage{ItemID: "item", Level: LogLevelInfo, Message: fmt.Sprint(k)}
+		}
+		// full batch is sent while the channel is still open
+		deadline := time.Now().Add(5 * time.Second)
+		for batches() == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		flushed <- batches() == 1
+		logMessages <- &LogMessage{ItemID: "item", Level: LogLevelInfo, Message: "last"}
+	}()
+	if err := rp.client().SendLogBatches(logMessages); err != nil {
+		t.Fatal(err)
+	}
+	if !<-flushed {
+		t.Error("expected full batch to be sent before the channel is closed")
+	}
+	if n := batches(); n != 2 {
+		t.Errorf("expected 2 log requests, got %d", n)
+	}
+}
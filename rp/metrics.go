@@ -0,0 +1,58 @@
+package rp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// promLabelEscaper escapes label values of Prometheus text exposition format
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePromMetrics writes report Summary totals in Prometheus text exposition format, e.g. as .prom file for
+// node exporter textfile collector. Every metric is written with given labels, label names should be valid
+// Prometheus label names
+func (report *XMLReport) WritePromMetrics(w io.Writer, labels map[string]string) error {
+	summary := report.Summary()
+	metrics := []struct {
+		name, metricType, help string
+		value                  string
+	}{
+		{"junit_tests_total", "counter", "Total number of tests.", strconv.Itoa(summary.Tests)},
+		{"junit_failures_total", "counter", "Total number of failed tests.", strconv.Itoa(summary.Failures)},
+		{"junit_errors_total", "counter", "Total number of errored tests.", strconv.Itoa(summary.Errors)},
+		{"junit_skipped_total", "counter", "Total number of skipped tests.", strconv.Itoa(summary.Skipped)},
+		{"junit_duration_seconds", "gauge", "Total duration of test suites in seconds.",
+			strconv.FormatFloat(summary.Duration.Seconds(), 'f', -1, 64)},
+	}
+
+	promLabels := promLabels(labels)
+	bw := bufio.NewWriter(w)
+	for _, metric := range metrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(bw, "# TYPE %s %s\n", metric.name, metric.metricType)
+		fmt.Fprintf(bw, "%s%s %s\n", metric.name, promLabels, metric.value)
+	}
+	return bw.Flush()
+}
+
+// promLabels formats labels sorted by name as {name="value",...}, empty for no labels
+func promLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+`="`+promLabelEscaper.Replace(labels[name])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,38 @@
+package rp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWritePromMetricsGolden(t *testing.T) {
+	report, err := LoadXMLReport("testdata/regroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	err = report.WritePromMetrics(&b, map[string]string{
+		"project": "rp-client",
+		"branch":  `feature/"quoted"\path`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := readTestFile(t, "testdata/metrics.prom"); b.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, b.String())
+	}
+}
+
+func TestWritePromMetricsWithoutLabels(t *testing.T) {
+	report, err := LoadXMLReport("testdata/split")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := report.WritePromMetrics(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b.Bytes(), []byte("\njunit_tests_total 2\n")) {
+		t.Errorf("expected metric without labels, got\n%s", b.String())
+	}
+}
@@ -0,0 +1,74 @@
+package rp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MultiClient publishes the same results to several Report Portal instances, e.g. staging and production mirrors
+type MultiClient struct {
+	clients      []*Client
+	abortOnError bool
+}
+
+// MultiClientOption is used to configure optional MultiClient settings
+type MultiClientOption func(*MultiClient)
+
+// WithAbortOnError enables stopping at the first instance failed to publish, remaining instances are skipped.
+// By default failure of one instance does not prevent publishing to the others
+func WithAbortOnError(abort bool) MultiClientOption {
+	return func(m *MultiClient) {
+		m.abortOnError = abort
+	}
+}
+
+// NewMultiClient creates MultiClient for given clients, instances are called in clients order
+func NewMultiClient(clients []*Client, opts ...MultiClientOption) *MultiClient {
+	m := &MultiClient{
+		clients: clients,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Publish uploads xml report to every instance as by Client.Publish, each instance publishes its own copy of launch.
+// Finish results are provided in clients order, nil for instance failed to publish or skipped.
+// Errors of all failed instances are joined and prefixed by instance url
+func (m *MultiClient) Publish(report *XMLReport, launch *Launch, opts ...PublishOption) ([]*FinishResult, error) {
+	finishResults := make([]*FinishResult, len(m.clients))
+	return finishResults, m.each(func(k int, c *Client) error {
+		var err error
+		finishResults[k], err = c.Publish(report, launch, opts...)
+		return err
+	})
+}
+
+// FinishAllUnfinished finishes unfinished launches and test items of every instance with given status
+func (m *MultiClient) FinishAllUnfinished(status ExecutionStatus) error {
+	return m.each(func(_ int, c *Client) error {
+		return c.FinishAllUnfinished(status)
+	})
+}
+
+// Close finishes all unfinished launches and test items of every instance as failed
+func (m *MultiClient) Close() error {
+	return m.FinishAllUnfinished(ExecutionStatusFailed)
+}
+
+// each calls fn for every client, errors are joined unless aborting on the first one
+func (m *MultiClient) each(fn func(k int, c *Client) error) error {
+	var errs []error
+	for k, c := range m.clients {
+		err := fn(k, c)
+		if err == nil {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", c.baseURL, err))
+		if m.abortOnError {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
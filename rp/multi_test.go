@@ -0,0 +1,41 @@
+package rp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMultiClientPublish(t *testing.T) {
+	report, err := LoadXMLReport("testdata/failing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, abort := range []bool{false, true} {
+		rps := []*fakeRP{newFakeRP(t), newFakeRP(t), newFakeRP(t)}
+		rps[1].failItem = isBroken
+		clients := make([]*Client, len(rps))
+		for k, rp := range rps {
+			clients[k] = rp.client()
+		}
+
+		m := NewMultiClient(clients, WithAbortOnError(abort))
+		launch := &Launch{Name: "multi", Tags: []string{"mirror"}}
+		finishResults, err := m.Publish(report, launch, WithFailFast(true))
+		if err == nil || !strings.Contains(err.Error(), rps[1].URL) {
+			t.Errorf("abort %t: expected error prefixed by failed instance url, got %v", abort, err)
+		}
+		if len(finishResults) != len(rps) {
+			t.Fatalf("abort %t: expected %d finish results, got %d", abort, len(rps), len(finishResults))
+		}
+		if finishResults[0] == nil || finishResults[1] != nil {
+			t.Errorf("abort %t: expected finish result of the first instance only, got %v", abort, finishResults)
+		}
+		// remaining instance is skipped only when aborting
+		if published := finishResults[2] != nil && len(rps[2].items) != 0; published == abort {
+			t.Errorf("abort %t: expected last instance published %t, got %d items", abort, !abort, len(rps[2].items))
+		}
+		if len(launch.Tags) != 1 {
+			t.Errorf("abort %t: expected launch tags to be kept, got %v", abort, launch.Tags)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package rp
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ClientOption is used to configure optional Client settings
+type ClientOption func(*Client)
+
+// Clock provides current time, it is used for launch, test item and log message time defaults
+type Clock interface {
+	Now() time.Time
+}
+
+// wallClock is default Clock based on time.Now
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+// WithClock sets clock used when start or end time is not provided, e.g. to freeze time in tests
+func WithClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// TypeMapper maps test item type to the value sent to Report Portal, e.g. for servers expecting other type strings
+type TypeMapper interface {
+	MapType(itemType TestItemType) string
+}
+
+// WithTypeMapper sets mapper of test item types sent on test item start, by default types are sent as is
+func WithTypeMapper(mapper TypeMapper) ClientOption {
+	return func(c *Client) {
+		c.typeMapper = mapper
+	}
+}
+
+// WithUserAgent overrides default "rp-client/<version>" User-Agent header of requests
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithRedactor sets function applied to every log message text just before sending, e.g. to scrub secrets
+func WithRedactor(redactor func(string) string) ClientOption {
+	return func(c *Client) {
+		c.redactor = redactor
+	}
+}
+
+// secretPatterns match common secrets in log messages, secret value is the last group
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)((?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key)["']?\s*[:=]\s*["']?)([^\s"'&,;]+)`),
+	regexp.MustCompile(`(?i)(bearer\s+)([\w\-.~+/]+=*)`),
+}
+
+// RedactSecrets is redactor replacing values of common secrets (passwords, tokens, api keys, bearer credentials) with ***
+func RedactSecrets(message string) string {
+	for _, pattern := range secretPatterns {
+		message = pattern.ReplaceAllString(message, "${1}***")
+	}
+	return message
+}
+
+// WithProjectPrefix sets path segment placed between api url and project, e.g. tenant
+// for multi-tenant installations: {apiURL}/{prefix}/{project}/launch
+func WithProjectPrefix(prefix string) ClientOption {
+	return func(c *Client) {
+		c.projectPrefix = prefix
+	}
+}
+
+// WithCompressedRequests enables gzip compression of request bodies larger than 1 KiB
+func WithCompressedRequests(compress bool) ClientOption {
+	return func(c *Client) {
+		c.compressRequests = compress
+	}
+}
+
+// WithLogLimit caps count of log messages per test item: first log messages are sent as usual, of the rest only
+// last ones are sent on test item finish, after '... [K lines omitted]' marker. By default log messages are not limited
+func WithLogLimit(first, last int) ClientOption {
+	return func(c *Client) {
+		if first+last > 0 {
+			c.logLimit = newLogLimiter(first, last)
+		}
+	}
+}
+
+// WithRequestLogger sets function called after every HTTP round trip to Report Portal, including retries,
+// with request method, url, response status and duration. Request bodies are not logged by default
+func WithRequestLogger(logger func(RequestInfo)) ClientOption {
+	return func(c *Client) {
+		c.requestLogger = logger
+	}
+}
+
+// WithRequestBodyLogging enables passing request bodies to WithRequestLogger logger
+func WithRequestBodyLogging(enable bool) ClientOption {
+	return func(c *Client) {
+		c.logRequestBody = enable
+	}
+}
+
+// WithRetry enables retry of requests failed with retryable error (see IsRetryable),
+// request is retried up to attempts times with fixed delay between retries unless WithBackoffStrategy is set
+func WithRetry(attempts int, delay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retryAttempts = attempts
+		c.retryDelay = delay
+	}
+}
+
+// WithBackoffStrategy sets exponential delay between retries enabled by WithRetry: first delay is base,
+// every next one is multiplied by factor and capped by max, jitter randomizes each delay in range [0, delay).
+// It is used instead of fixed WithRetry delay regardless of options order. Factor less than 1 is rejected
+func WithBackoffStrategy(base, max time.Duration, factor float64, jitter bool) ClientOption {
+	return func(c *Client) {
+		// NaN factor is rejected as well
+		if !(factor >= 1) {
+			log.Errorf("backoff factor %v could not be less than 1, fixed retry delay is kept", factor)
+			return
+		}
+		c.retryBackoff = &backoff{
+			base:   base,
+			max:    max,
+			factor: factor,
+			jitter: jitter,
+		}
+	}
+}
+
+// WithRetryBudget bounds total time of request attempts and retry waits, request fails without further retries
+// as soon as next retry would exceed the budget even if retry attempts remain. By default retries are not bounded by time
+func WithRetryBudget(total time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retryBudget = total
+	}
+}
+
+// ReportOption is used to configure optional XMLReport settings
+type ReportOption func(*XMLReport)
+
+// WithClassNameNormalizer sets function applied to test case class names, by default class names are kept as is
+func WithClassNameNormalizer(normalizer func(string) string) ReportOption {
+	return func(report *XMLReport) {
+		report.classNameNormalizer = normalizer
+	}
+}
+
+// WithModTimeFallback enables using report file modification time as start time of suites without timestamp,
+// so such suites are not sorted as started at zero time
+func WithModTimeFallback(fallback bool) ReportOption {
+	return func(report *XMLReport) {
+		report.modTimeFallback = fallback
+	}
+}
+
+// WithDurationClamp enables limiting suite and test case times to [0, max] range on load,
+// e.g. for garbage negative or huge times emitted by some runners. By default times are not clamped
+func WithDurationClamp(max time.Duration) ReportOption {
+	return func(report *XMLReport) {
+		report.maxDuration = max
+	}
+}
+
+// WithRawXML enables keeping XML of top level suites for SuiteRawXML, e.g. to attach it with WithSuiteRawXML.
+// Report file content is not kept by default
+func WithRawXML(keep bool) ReportOption {
+	return func(report *XMLReport) {
+		report.rawXML = keep
+	}
+}
+
+// WithMergeSplitSuites enables merging suites with the same package and name into single suite,
+// e.g. for one logical suite written by parallel runners from several hosts as separate files
+func WithMergeSplitSuites(merge bool) ReportOption {
+	return func(report *XMLReport) {
+		report.mergeSplitSuites = merge
+	}
+}
+
+// WithFailureTypeLevels sets log levels of test case failure and error messages by their type substrings,
+// e.g. {"WARN": LogLevelWarn}. Messages of not mapped types are logged as errors
+func WithFailureTypeLevels(levels map[string]LogLevel) ReportOption {
+	return func(report *XMLReport) {
+		report.failureTypeLevels = levels
+	}
+}
+
+// WithSuiteOverheadThreshold enables suite log about setup/teardown overhead when suite time exceeds
+// times of its test cases and nested suites by more than threshold. By default overhead is not logged
+func WithSuiteOverheadThreshold(threshold time.Duration) ReportOption {
+	return func(report *XMLReport) {
+		report.overheadThreshold = threshold
+	}
+}
+
+// WithCodeRef enables setting test case code reference to its full name (class name and test case name),
+// so Report Portal keeps test case history by code reference
+func WithCodeRef(codeRef bool) ReportOption {
+	return func(report *XMLReport) {
+		report.codeRef = codeRef
+	}
+}
+
+// WithStartTimeFromFirstCase enables using the earliest test case timestamp as suite start time, e.g. for runners
+// setting suite timestamp at queue time. Suites without timestamped test cases keep suite timestamp
+func WithStartTimeFromFirstCase(enable bool) ReportOption {
+	return func(report *XMLReport) {
+		report.startFromFirstCase = enable
+	}
+}
+
+// WithSystemErrFailures enables reporting test case as failed when it has neither failure nor error element,
+// but has non-passing status attribute (e.g. 'failed') and system-err output, for runners signalling failures
+// only by stderr. Disabled by default to avoid false failures of cases writing to stderr
+func WithSystemErrFailures(enable bool) ReportOption {
+	return func(report *XMLReport) {
+		report.systemErrFailures = enable
+	}
+}
+
+// WithDefaultPackage sets package prefixed to name of suites without package attribute, e.g. 'default',
+// so unqualified suites are grouped consistently. By default such suites are named without package
+func WithDefaultPackage(packageName string) ReportOption {
+	return func(report *XMLReport) {
+		report.defaultPackage = packageName
+	}
+}
+
+// WithPartialWriteRetry enables reading report file once again after delay when it could not be decoded
+// and it was modified less than window ago, e.g. when file is still being written by test runner.
+// By default such files are skipped right away
+func WithPartialWriteRetry(window, delay time.Duration) ReportOption {
+	return func(report *XMLReport) {
+		report.partialWriteWindow = window
+		report.partialWriteDelay = delay
+	}
+}
+
+// WithMinDuration sets minimal duration of suites and test cases, shorter times are raised to it, e.g. 1ms for
+// sub-millisecond cases. Zero and missing times are replaced with 100ms regardless of the option
+func WithMinDuration(min time.Duration) ReportOption {
+	return func(report *XMLReport) {
+		report.minDuration = min
+	}
+}
+
+// WithReadBufferSize sets minimal capacity of pooled buffers report files are read with, e.g. for reports
+// of many large files. By default buffers are sized by file size. Buffers grown over 4 times the size are not reused
+func WithReadBufferSize(size int) ReportOption {
+	return func(report *XMLReport) {
+		report.readBufferSize = size
+	}
+}
+
+// WithCounterReconciliation enables deriving suite status from its test cases when suite failures or errors
+// attributes disagree with failures and errors of the test cases, warning about the mismatch.
+// By default suite status is derived from the attributes
+func WithCounterReconciliation(reconcile bool) ReportOption {
+	return func(report *XMLReport) {
+		report.reconcileCounters = reconcile
+	}
+}
+
+// WithItemStartTimeClamp controls clamping of test case start time to its suite span and nested suite
+// start time to its parent suite span, since Report Portal rejects child item started before its parent.
+// Suite end time is stretched to the end of the last child outlasting the suite. Clamping is enabled by default
+func WithItemStartTimeClamp(clamp bool) ReportOption {
+	return func(report *XMLReport) {
+		report.startClamp = clamp
+	}
+}
+
+// WithSuiteNamePrefix sets prefix added to every suite name, e.g. '[chrome] ' to tell apart suites run
+// in several configurations within one launch
+func WithSuiteNamePrefix(prefix string) ReportOption {
+	return func(report *XMLReport) {
+		report.suiteNamePrefix = prefix
+	}
+}
+
+// WithSuiteNameSuffix sets suffix added to every suite name, e.g. ' (linux)'
+func WithSuiteNameSuffix(suffix string) ReportOption {
+	return func(report *XMLReport) {
+		report.suiteNameSuffix = suffix
+	}
+}
+
+// WithSuiteOrder sets order of report top level suites, by default suites are ordered by start time
+func WithSuiteOrder(order SuiteOrder) ReportOption {
+	return func(report *XMLReport) {
+		report.suiteOrder = order
+	}
+}
+
+// WithNoSort disables ordering of report top level suites, so suites are kept in read order of report files,
+// e.g. to correlate suites with file names when debugging parsing. Intended for diagnostics only
+func WithNoSort(noSort bool) ReportOption {
+	return func(report *XMLReport) {
+		report.noSort = noSort
+	}
+}
+
+// WithChunkedDirScan sets count of suites StreamXMLReport keeps in memory for ordering, suites are sent
+// in chunks of the same size. Default window is 100 suites
+func WithChunkedDirScan(window int) ReportOption {
+	return func(report *XMLReport) {
+		report.chunkWindow = window
+	}
+}
+
+// NormalizePathClassName is class name normalizer converting Windows and Unix path separators to dots,
+// so the same tests executed on different platforms share history
+func NormalizePathClassName(className string) string {
+	return strings.NewReplacer("\\", ".", "/", ".").Replace(className)
+}
+
+// PublishOption is used to configure optional Publish settings
+type PublishOption func(*publishConfig)
+
+// WithFlattenSingleSuite enables reporting test cases directly under the launch when report has exactly one suite
+func WithFlattenSingleSuite(flatten bool) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.flattenSingleSuite = flatten
+	}
+}
+
+// WithLaunchStartedCallback sets function called with launch id right after launch is started,
+// before any test item is uploaded, e.g. to print launch link early
+func WithLaunchStartedCallback(callback func(launchID string)) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.launchStarted = callback
+	}
+}
+
+// WithAttributeFromEnv adds launch attributes as 'key:value' tags with values of env vars mapped by attribute key,
+// e.g. {"branch": "GIT_BRANCH", "build": "BUILD_NUMBER"}. Missing or empty env vars are skipped
+func WithAttributeFromEnv(envVars map[string]string) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.envAttributes = envVars
+	}
+}
+
+// WithSuiteRawXML enables attaching XML of top level suites kept with WithRawXML report option as xml file,
+// attachment content is scrubbed by client redactor
+func WithSuiteRawXML(attach bool) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.suiteRawXML = attach
+	}
+}
+
+// WithLaunchName sets launch name template, e.g. 'MyApp #{env.BUILD_NUMBER} on {date}'.
+// Supported placeholders are {env.VAR} for env var value, {date} for launch start date and {count} for suites count
+func WithLaunchName(template string) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.launchName = template
+	}
+}
+
+// WithItemAttributeMapper sets function deriving attributes of every published suite and test case,
+// e.g. to tag tests by naming convention. Returned attributes are added to item as 'key:value' tags,
+// attribute with empty value is added as 'key' tag
+func WithItemAttributeMapper(mapper func(item *TestItem) map[string]string) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.attributeMapper = mapper
+	}
+}
+
+// WithOnCaseFailed sets callback called by Publish for every failed test case once it is uploaded, e.g. to
+// notify about failures. Suite name is passed as provided by Suite. Callback is called synchronously, so it should
+// not block publishing for long
+func WithOnCaseFailed(callback func(suiteName, caseName string, failure *LogMessage)) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.onCaseFailed = callback
+	}
+}
+
+// WithAssertionsAttribute enables adding report TotalAssertions to launch as 'assertions:<n>' tag
+func WithAssertionsAttribute(enable bool) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.assertionsTag = enable
+	}
+}
+
+// WithLogPolicy sets which test cases logs are uploaded, by default only logs of failed test cases are uploaded
+func WithLogPolicy(policy LogPolicy) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.logPolicy = policy
+	}
+}
+
+// WithFailFast enables stopping Publish on the first upload error, by default publishing goes on
+// and all upload errors are returned as *PublishError once launch is finished
+func WithFailFast(failFast bool) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.failFast = failFast
+	}
+}
+
+// WithParentPathName enables reporting test cases under suite items built from their dot separated class name,
+// e.g. case of class 'a.b.C' is reported under 'a' > 'b' > 'C' items nested into its suite. Cases of the same
+// suite sharing class name prefix share its items
+func WithParentPathName(enable bool) PublishOption {
+	return func(cfg *publishConfig) {
+		cfg.parentPathName = enable
+	}
+}
@@ -0,0 +1,54 @@
+package rp
+
+import (
+	"sync"
+	"time"
+)
+
+// ordinalStep is start time shift of item started not after its previous sibling, the smallest step of TimestampLayout
+const ordinalStep = time.Millisecond
+
+// itemOrdinals tracks ordinal and start time of the last started item with ordinal per parent item,
+// it is safe for concurrent use
+type itemOrdinals struct {
+	mu   sync.Mutex
+	last map[string]itemOrdinal
+}
+
+type itemOrdinal struct {
+	ordinal int
+	start   time.Time
+}
+
+func newItemOrdinals() *itemOrdinals {
+	return &itemOrdinals{
+		last: make(map[string]itemOrdinal),
+	}
+}
+
+// ordinalParentKey is parent item id, or launch id for top level item
+func ordinalParentKey(parentItemID, launchID string) string {
+	if len(parentItemID) != 0 {
+		return parentItemID
+	}
+	return "launch/" + launchID
+}
+
+// start provides start time of item with given ordinal, item following previous sibling with lower ordinal
+// is started at least ordinalStep after it
+func (o *itemOrdinals) start(parentKey string, ordinal int, start time.Time) time.Time {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if prev, ok := o.last[parentKey]; ok && prev.ordinal < ordinal && !start.After(prev.start) {
+		start = prev.start.Add(ordinalStep)
+	}
+	o.last[parentKey] = itemOrdinal{ordinal: ordinal, start: start}
+	return start
+}
+
+// finish is used to stop tracking children of finished parent
+func (o *itemOrdinals) finish(parentKey string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.last, parentKey)
+}
@@ -0,0 +1,122 @@
+package rp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pathItems holds suite items started for class name path segments of single suite test cases
+type pathItems struct {
+	items map[string]*pathItem
+	// cases are ids of started test cases by full name
+	cases map[string]string
+	// paths are item paths in start order, so nested items are finished before their parents
+	paths []string
+}
+
+// pathItem is started class name path item with result merged from its test cases
+type pathItem struct {
+	id     string
+	result *ExecutionResult
+}
+
+func newPathItems() *pathItems {
+	return &pathItems{
+		items: make(map[string]*pathItem),
+		cases: make(map[string]string),
+	}
+}
+
+// splitClassName splits class name into path segments, empty segments are skipped
+func splitClassName(className string) []string {
+	return strings.FieldsFunc(className, func(r rune) bool {
+		return r == '.'
+	})
+}
+
+// startPathItems starts missing class name path items of test case and provides id of the innermost one,
+// suite id is provided for case without class name. Empty id is provided when path item could not be started
+func (c *Client) startPathItems(report *XMLReport, i, j int, launchID, suiteID string, paths *pathItems, cfg *publishConfig) (string, error) {
+	parentID := suiteID
+	segments := splitClassName(report.TestCaseClassName(i, j))
+	for k, name := range segments {
+		path := strings.Join(segments[:k+1], ".")
+		if item, ok := paths.items[path]; ok {
+			parentID = item.id
+			continue
+		}
+
+		pathSuite := &TestItem{
+			LaunchID:  launchID,
+			Name:      name,
+			Type:      TestItemTypeSuite,
+			StartTime: report.TestCaseStartTime(i, j),
+		}
+		cfg.mapAttributes(pathSuite)
+		pathID := c.StartTestItem(parentID, pathSuite)
+		if pathID == nil {
+			return "", cfg.handle(fmt.Errorf("could not start suite '%s'", path))
+		}
+		paths.items[path] = &pathItem{
+			id: pathID.ID,
+			result: &ExecutionResult{
+				Status: ExecutionStatusSkipped,
+			},
+		}
+		paths.paths = append(paths.paths, path)
+		parentID = pathID.ID
+	}
+	return parentID, nil
+}
+
+// add merges test case result into results of its class name path items: item is failed if any case failed,
+// skipped if all cases are skipped, otherwise passed, and ends at the latest case end time
+func (paths *pathItems) add(className string, result *ExecutionResult) {
+	segments := splitClassName(className)
+	for k := range segments {
+		item, ok := paths.items[strings.Join(segments[:k+1], ".")]
+		if !ok {
+			continue
+		}
+		if result.EndTime.After(item.result.EndTime) {
+			item.result.EndTime = result.EndTime
+		}
+		switch {
+		case result.IsFailed():
+			item.result.Status = ExecutionStatusFailed
+		case result.IsPassed() && item.result.IsSkipped():
+			item.result.Status = ExecutionStatusPassed
+		}
+	}
+}
+
+// addCase registers started test case, so messages could be routed to it by its full name
+func (paths *pathItems) addCase(fullName, id string) {
+	paths.cases[fullName] = id
+}
+
+// route provides id of class name path item or test case by item path, default id for other paths
+func (paths *pathItems) route(itemPath, defaultID string) string {
+	if paths == nil {
+		return defaultID
+	}
+	if item, ok := paths.items[itemPath]; ok {
+		return item.id
+	}
+	if id, ok := paths.cases[itemPath]; ok {
+		return id
+	}
+	return defaultID
+}
+
+// finishPathItems finishes started class name path items, nested items first
+func (c *Client) finishPathItems(paths *pathItems, cfg *publishConfig) error {
+	for k := len(paths.paths) - 1; k >= 0; k-- {
+		item := paths.items[paths.paths[k]]
+		err := cfg.handle(c.FinishTestItem(item.id, item.result))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
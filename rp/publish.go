@@ -0,0 +1,345 @@
+package rp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// launchNamePlaceholder matches {placeholder} of launch name template
+var launchNamePlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// publishConfig holds optional Publish settings
+type publishConfig struct {
+	flattenSingleSuite bool
+	launchStarted      func(launchID string)
+	envAttributes      map[string]string
+	suiteRawXML        bool
+	launchName         string
+	attributeMapper    func(item *TestItem) map[string]string
+	onCaseFailed       func(suiteName, caseName string, failure *LogMessage)
+	assertionsTag      bool
+	logPolicy          LogPolicy
+	failFast           bool
+	parentPathName     bool
+
+	// errs are upload errors publishing kept going after
+	errs []error
+	// launchID is id of launch started by publishing, its items left in progress are finished on failure
+	launchID string
+}
+
+// handle decides if publishing should stop on upload error: with fail fast error is returned,
+// otherwise it is kept and nil is returned so publishing goes on
+func (cfg *publishConfig) handle(err error) error {
+	if err == nil || cfg.failFast {
+		return err
+	}
+	log.Error(err)
+	cfg.errs = append(cfg.errs, err)
+	return nil
+}
+
+// err provides *PublishError with all upload errors publishing kept going after, nil without errors
+func (cfg *publishConfig) err() error {
+	if len(cfg.errs) == 0 {
+		return nil
+	}
+	return &PublishError{
+		Errors: cfg.errs,
+	}
+}
+
+// LogPolicy controls which test cases logs are uploaded by Publish
+type LogPolicy int
+
+const (
+	// LogPolicyFailuresOnly uploads logs of failed test cases only
+	LogPolicyFailuresOnly LogPolicy = iota
+	// LogPolicyAllCases uploads logs of all test cases, including passed and skipped ones
+	LogPolicyAllCases
+)
+
+// Publish uploads xml report to Report Portal as new launch, launch start time defaults to report launch start time
+// and empty description defaults to report DefaultDescription. Report LaunchAttributes are added to launch as 'key:value' tags.
+// Launch is finished at report launch end time and finish result is returned. Publishing goes on after test item
+// upload error, skipping items which could not be started, and *PublishError with all upload errors is returned
+// with finish result. Test items are started with ordinals of their report position, so items sharing start time
+// keep report order.
+// With WithFailFast publishing stops on the first error. On failure the launch and its test items left in progress
+// are finished as failed. Launch is not changed, defaults and tags are applied to its copy
+func (c *Client) Publish(report *XMLReport, launch *Launch, opts ...PublishOption) (*FinishResult, error) {
+	cfg := &publishConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	finishResult, err := c.publish(report, copyLaunch(launch), cfg)
+	if err != nil {
+		if len(cfg.launchID) != 0 {
+			c.finishLaunchUnfinished(cfg.launchID, ExecutionStatusFailed)
+		}
+		return finishResult, err
+	}
+	return finishResult, nil
+}
+
+// publish uploads xml report as new launch
+func (c *Client) publish(report *XMLReport, launch *Launch, cfg *publishConfig) (*FinishResult, error) {
+	if report.SuitesCount() == 0 {
+		return nil, errors.New("report has no suites")
+	}
+	if launch.StartTime.IsZero() {
+		launch.StartTime = report.LaunchStartTime()
+	}
+	if len(launch.Description) == 0 {
+		launch.Description = report.DefaultDescription()
+	}
+	cfg.prepareLaunch(report, launch)
+	if cfg.assertionsTag {
+		launch.Tags = append(launch.Tags, "assertions:"+strconv.Itoa(report.TotalAssertions()))
+	}
+
+	launchID, err := c.startPublishedLaunch(launch, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.flattenSingleSuite && report.SuitesCount() == 1 {
+		for j := 0; j < report.TesCaseCount(0); j++ {
+			err := c.publishTestCase(report, 0, j, launchID, "", nil, cfg)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		err := c.publishSuites(report, launchID, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	finishResult, err := c.FinishLaunch(launchID, &ExecutionResult{
+		EndTime: report.LaunchEndTime(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return finishResult, cfg.err()
+}
+
+// copyLaunch copies launch with its tags, so publishing does not change launch of the caller
+func copyLaunch(launch *Launch) *Launch {
+	copied := *launch
+	copied.Tags = append([]string(nil), launch.Tags...)
+	return &copied
+}
+
+// prepareLaunch applies launch name template and adds report and env attributes to launch tags
+func (cfg *publishConfig) prepareLaunch(report *XMLReport, launch *Launch) {
+	if len(cfg.launchName) != 0 {
+		launch.Name = resolveLaunchName(cfg.launchName, launch, report)
+	}
+	launch.Tags = append(launch.Tags, attributeTags(report.LaunchAttributes())...)
+	launch.Tags = append(launch.Tags, envAttributes(cfg.envAttributes)...)
+}
+
+// startPublishedLaunch starts launch and notifies launch started callback
+func (c *Client) startPublishedLaunch(launch *Launch, cfg *publishConfig) (string, error) {
+	launchID := c.StartLaunch(launch)
+	if launchID == nil {
+		return "", errors.New("could not start launch")
+	}
+	cfg.launchID = launchID.ID
+	if cfg.launchStarted != nil {
+		cfg.launchStarted(launchID.ID)
+	}
+	return launchID.ID, nil
+}
+
+// publishSuites uploads all top level suites of report with their nested suites
+func (c *Client) publishSuites(report *XMLReport, launchID string, cfg *publishConfig) error {
+	ordinal := 0
+	for i := 0; i < report.SuitesCount(); i++ {
+		if report.SuiteParent(i) >= 0 {
+			continue
+		}
+		ordinal++
+		err := c.publishSuite(report, i, ordinal, launchID, "", cfg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishSuite uploads suite with its test cases and nested suites under specified parent item,
+// nested suites are ordered after test cases of the suite
+func (c *Client) publishSuite(report *XMLReport, i, ordinal int, launchID, parentID string, cfg *publishConfig) error {
+	suite := report.Suite(i)
+	suite.LaunchID = launchID
+	suite.Ordinal = ordinal
+	cfg.mapAttributes(suite)
+	suiteID := c.StartTestItem(parentID, suite)
+	if suiteID == nil {
+		return cfg.handle(fmt.Errorf("could not start suite '%s'", suite.Name))
+	}
+
+	if raw := report.SuiteRawXML(i); cfg.suiteRawXML && len(raw) != 0 {
+		if c.redactor != nil {
+			raw = []byte(c.redactor(string(raw)))
+		}
+		err := c.SendAttachment(&LogMessage{
+			ItemID:  suiteID.ID,
+			Time:    suite.StartTime,
+			Level:   LogLevelInfo,
+			Message: "suite xml report",
+		}, &Attachment{
+			Name:        suite.Name + ".xml",
+			ContentType: "application/xml",
+			Content:     raw,
+		})
+		if err := cfg.handle(err); err != nil {
+			return err
+		}
+	}
+
+	if overheadLog := report.SuiteOverheadLog(i); overheadLog != nil {
+		overheadLog.ItemID = suiteID.ID
+		c.SendMesssage(overheadLog)
+	}
+
+	var paths *pathItems
+	if cfg.parentPathName {
+		paths = newPathItems()
+	}
+	for j := 0; j < report.TesCaseCount(i); j++ {
+		caseParentID := suiteID.ID
+		if paths != nil {
+			var err error
+			caseParentID, err = c.startPathItems(report, i, j, launchID, suiteID.ID, paths, cfg)
+			if err != nil {
+				return err
+			}
+			if len(caseParentID) == 0 {
+				continue
+			}
+		}
+		err := c.publishTestCase(report, i, j, launchID, caseParentID, paths, cfg)
+		if err != nil {
+			return err
+		}
+		if paths != nil {
+			paths.add(report.TestCaseClassName(i, j), report.TestCaseResult(i, j))
+		}
+	}
+	if paths != nil {
+		err := c.finishPathItems(paths, cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	for k, child := range report.SuiteChildren(i) {
+		err := c.publishSuite(report, child, report.TesCaseCount(i)+k+1, launchID, suiteID.ID, cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cfg.handle(c.FinishTestItem(suiteID.ID, report.SuiteResult(i)))
+}
+
+// publishTestCase uploads test case with its logs under specified parent item, logs are routed by item path
+// to class name path items if any, otherwise to the test case
+func (c *Client) publishTestCase(report *XMLReport, i, j int, launchID, parentID string, paths *pathItems, cfg *publishConfig) error {
+	tCase := report.TestCase(i, j)
+	tCase.LaunchID = launchID
+	tCase.Ordinal = j + 1
+	cfg.mapAttributes(tCase)
+	tCaseID := c.StartTestItem(parentID, tCase)
+	if tCaseID == nil {
+		return cfg.handle(fmt.Errorf("could not start test case '%s'", tCase.Name))
+	}
+	if paths != nil {
+		paths.addCase(report.TestCaseFullName(i, j), tCaseID.ID)
+	}
+
+	result := report.TestCaseResult(i, j)
+	if cfg.logPolicy == LogPolicyAllCases || result.IsFailed() {
+		for _, logMessage := range report.TestCaseLogs(i, j) {
+			logMessage.ItemID = paths.route(logMessage.ItemPath, tCaseID.ID)
+			c.SendMesssage(logMessage)
+		}
+	}
+
+	err := c.FinishTestItem(tCaseID.ID, result)
+	if err != nil {
+		return cfg.handle(err)
+	}
+	if cfg.onCaseFailed != nil && result.IsFailed() {
+		failure := report.TestCaseFailure(i, j)
+		failure.ItemID = tCaseID.ID
+		cfg.onCaseFailed(report.Suite(i).Name, tCase.Name, failure)
+	}
+	return nil
+}
+
+// mapAttributes adds attributes derived by attribute mapper to test item tags
+func (cfg *publishConfig) mapAttributes(item *TestItem) {
+	if cfg.attributeMapper == nil {
+		return
+	}
+	item.Tags = append(item.Tags, attributeTags(cfg.attributeMapper(item))...)
+}
+
+// envAttributes provides 'key:value' launch tags for attribute keys mapped to non-empty env vars, sorted by key
+func envAttributes(envVars map[string]string) []string {
+	attributes := make(map[string]string)
+	for key, envVar := range envVars {
+		if value := os.Getenv(envVar); len(value) != 0 {
+			attributes[key] = value
+		}
+	}
+	return attributeTags(attributes)
+}
+
+// attributeTags provides attributes as 'key:value' tags sorted by key, attribute with empty value is tagged by key only
+func attributeTags(attributes map[string]string) []string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tags := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if len(attributes[key]) == 0 {
+			tags = append(tags, key)
+			continue
+		}
+		tags = append(tags, key+":"+attributes[key])
+	}
+	return tags
+}
+
+// resolveLaunchName replaces launch name template placeholders: {env.VAR} with env var value, {date} with
+// launch start date and {count} with report suites count. Unknown placeholders are kept as is
+func resolveLaunchName(template string, launch *Launch, report *XMLReport) string {
+	return launchNamePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		switch {
+		case strings.HasPrefix(name, "env."):
+			return os.Getenv(strings.TrimPrefix(name, "env."))
+		case name == "date":
+			return launch.StartTime.Format("2006-01-02")
+		case name == "count":
+			return strconv.Itoa(report.SuitesCount())
+		}
+		return placeholder
+	})
+}
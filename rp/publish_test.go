@@ -0,0 +1,646 @@
+package rp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublishOrdersSameTimestampCases(t *testing.T) {
+	report, err := LoadXMLReport("testdata/ordinal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	_, err = rp.client().Publish(report, &Launch{Name: "ordinal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suites := rp.children("")
+	if len(suites) != 1 {
+		t.Fatalf("expected 1 suite, got %d", len(suites))
+	}
+	cases := rp.children(suites[0].ID)
+	want := []string{"first", "second", "third"}
+	if len(cases) != len(want) {
+		t.Fatalf("expected %d cases, got %d", len(want), len(cases))
+	}
+	var prev time.Time
+	for k, tCase := range cases {
+		if tCase.Name != want[k] {
+			t.Errorf("case %d: expected %s, got %s", k, want[k], tCase.Name)
+		}
+		start, err := time.Parse(TimestampLayout, tCase.StartTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !start.After(prev) {
+			t.Errorf("case %s: expected start %s after previous case start %s", tCase.Name, start, prev)
+		}
+		prev = start
+	}
+}
+
+func TestStartTestItemOrdinal(t *testing.T) {
+	rp := newFakeRP(t)
+	c := rp.client()
+	start := time.Date(2017, 5, 5, 20, 3, 50, 0, time.UTC)
+	for ordinal := 1; ordinal <= 3; ordinal++ {
+		item := &TestItem{LaunchID: "launch", Name: "case", StartTime: start, Type: TestItemTypeStep, Ordinal: ordinal}
+		if c.StartTestItem("suite", item) == nil {
+			t.Fatal("could not start test item")
+		}
+		want := start.Add(time.Duration(ordinal-1) * ordinalStep)
+		if !item.StartTime.Equal(want) {
+			t.Errorf("ordinal %d: expected start %s, got %s", ordinal, want, item.StartTime)
+		}
+	}
+
+	// items without ordinal and items of other parents are not shifted
+	for _, parentID := range []string{"other", "suite"} {
+		item := &TestItem{LaunchID: "launch", Name: "case", StartTime: start, Type: TestItemTypeStep}
+		c.StartTestItem(parentID, item)
+		if !item.StartTime.Equal(start) {
+			t.Errorf("parent %s: expected start %s of item without ordinal, got %s", parentID, start, item.StartTime)
+		}
+	}
+}
+
+func TestPublishSuiteRawXML(t *testing.T) {
+	report, err := LoadXMLReport("testdata/raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < report.SuitesCount(); i++ {
+		if raw := report.SuiteRawXML(i); raw != nil {
+			t.Errorf("expected no raw xml kept without WithRawXML, got %d bytes", len(raw))
+		}
+	}
+
+	report, err = LoadXMLReport("testdata/raw", WithRawXML(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	redactor := func(s string) string { return strings.Replace(s, "secret", "***", -1) }
+	_, err = rp.client(WithRedactor(redactor)).Publish(report, &Launch{Name: "raw"}, WithSuiteRawXML(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aggregate := readTestFile(t, "testdata/raw/aggregate.xml")
+	want := map[string]string{
+		"p.Alpha.xml": redactor(suiteElement(t, aggregate, "Alpha")),
+		"p.Beta.xml":  suiteElement(t, aggregate, "Beta"),
+		"p.Gamma.xml": readTestFile(t, "testdata/raw/single.xml"),
+	}
+	if len(rp.files) != len(want) {
+		t.Fatalf("expected %d attachments, got %d", len(want), len(rp.files))
+	}
+	for _, file := range rp.files {
+		if file.Content != want[file.Name] {
+			t.Errorf("attachment %s: expected\n%s\ngot\n%s", file.Name, want[file.Name], file.Content)
+		}
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// suiteElement provides <testsuite> element of aggregate document by suite name
+func suiteElement(t *testing.T, aggregate, name string) string {
+	start := strings.Index(aggregate, `<testsuite name="`+name+`"`)
+	if start < 0 {
+		t.Fatalf("no suite %s", name)
+	}
+	end := start + strings.Index(aggregate[start:], "</testsuite>") + len("</testsuite>")
+	return aggregate[start:end]
+}
+
+func TestPublishRoutesLogsByItemPath(t *testing.T) {
+	report, err := LoadXMLReport("testdata/paths")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	_, err = rp.client().Publish(report, &Launch{Name: "paths"}, WithParentPathName(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// suite > a > b > C > cases
+	parent := rp.children("")
+	for _, name := range []string{"a.Paths", "a", "b", "C"} {
+		if len(parent) != 1 || parent[0].Name != name {
+			t.Fatalf("expected single item %s, got %+v", name, parent)
+		}
+		if name != "C" {
+			parent = rp.children(parent[0].ID)
+		}
+	}
+	classItem := parent[0]
+	cases := rp.children(classItem.ID)
+	if len(cases) != 3 {
+		t.Fatalf("expected 3 cases of class item, got %d", len(cases))
+	}
+
+	want := map[string][]string{
+		cases[0].ID:  {"first failed"},
+		cases[1].ID:  {"second failed"},
+		cases[2].ID:  nil,
+		classItem.ID: {"setup failed"},
+	}
+	for id, messages := range want {
+		if got := rp.itemLogs(id); !reflect.DeepEqual(got, messages) {
+			t.Errorf("item %s: expected logs %q, got %q", id, messages, got)
+		}
+	}
+}
+
+func TestPublishSharedPathItems(t *testing.T) {
+	report, err := LoadXMLReport("testdata/sharedpaths")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	_, err = rp.client().Publish(report, &Launch{Name: "shared paths"}, WithParentPathName(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// suite > a > b > C, D: classes of the same package share a and b items
+	suites := rp.children("")
+	if len(suites) != 1 {
+		t.Fatalf("expected single suite, got %+v", suites)
+	}
+	var names []string
+	counts := make(map[string]int)
+	for _, item := range rp.items {
+		counts[item.Name]++
+	}
+	for _, name := range []string{"a", "b", "C", "D"} {
+		if counts[name] != 1 {
+			names = append(names, name)
+		}
+	}
+	if len(names) != 0 {
+		t.Fatalf("expected single item of each path segment, got duplicated or missing %v in %+v", names, rp.items)
+	}
+
+	a := rp.children(suites[0].ID)
+	if len(a) != 1 || a[0].Name != "a" {
+		t.Fatalf("expected single a item, got %+v", a)
+	}
+	b := rp.children(a[0].ID)
+	if len(b) != 1 || b[0].Name != "b" {
+		t.Fatalf("expected single b item, got %+v", b)
+	}
+	classes := rp.children(b[0].ID)
+	if len(classes) != 2 || classes[0].Name != "C" || classes[1].Name != "D" {
+		t.Fatalf("expected C and D items under b, got %+v", classes)
+	}
+	for k, name := range []string{"first", "second"} {
+		if cases := rp.children(classes[k].ID); len(cases) != 1 || cases[0].Name != name {
+			t.Errorf("expected case %s under %s, got %+v", name, classes[k].Name, cases)
+		}
+	}
+}
+
+func TestPublishNestedSuites(t *testing.T) {
+	report, err := LoadXMLReport("testdata/nested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	_, err = rp.client().Publish(report, &Launch{Name: "nested"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tree renders item and its children by start order as name(children...)
+	var tree func(item fakeItem) string
+	tree = func(item fakeItem) string {
+		var children []string
+		for _, child := range rp.children(item.ID) {
+			children = append(children, tree(child))
+		}
+		if len(children) == 0 {
+			return item.Name
+		}
+		return item.Name + "(" + strings.Join(children, " ") + ")"
+	}
+	roots := rp.children("")
+	if len(roots) != 1 {
+		t.Fatalf("expected single top level suite, got %d", len(roots))
+	}
+	want := "n.Root(root case n.Child(child case n.Grandchild(grandchild case)) n.Sibling(sibling case))"
+	if got := tree(roots[0]); got != want {
+		t.Errorf("expected item tree\n%s\ngot\n%s", want, got)
+	}
+
+	for _, item := range rp.items {
+		if status, ok := rp.finishedStatus(item.ID); !ok {
+			t.Errorf("item %s is not finished", item.Name)
+		} else if item.Name == "n.Child" && status != string(ExecutionStatusFailed) {
+			t.Errorf("expected suite with failed case to be failed, got %s", status)
+		}
+	}
+}
+
+// isBroken makes start of broken test cases fail
+func isBroken(name string) bool {
+	return strings.HasPrefix(name, "broken")
+}
+
+func TestPublishAggregatesErrors(t *testing.T) {
+	report, err := LoadXMLReport("testdata/failing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	rp.failItem = isBroken
+	finishResult, err := rp.client().Publish(report, &Launch{Name: "errors"})
+
+	var publishErr *PublishError
+	if !errors.As(err, &publishErr) {
+		t.Fatalf("expected *PublishError, got %v", err)
+	}
+	if len(publishErr.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(publishErr.Errors), err)
+	}
+	for k, name := range []string{"broken one", "broken two"} {
+		if !strings.Contains(publishErr.Errors[k].Error(), name) {
+			t.Errorf("error %d: expected error of %s, got %v", k, name, publishErr.Errors[k])
+		}
+		if !errors.Is(err, publishErr.Errors[k]) {
+			t.Errorf("expected error %d to be found by errors.Is", k)
+		}
+	}
+	if finishResult == nil {
+		t.Error("expected finish result of launch published with errors")
+	}
+	// publishing goes on after errors
+	suites := rp.children("")
+	if len(suites) != 1 || len(rp.children(suites[0].ID)) != 2 {
+		t.Errorf("expected first and last cases to be published, got %+v", rp.items)
+	}
+}
+
+func TestPublishFailFast(t *testing.T) {
+	report, err := LoadXMLReport("testdata/failing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	rp.failItem = isBroken
+	_, err = rp.client().Publish(report, &Launch{Name: "fail fast"}, WithFailFast(true))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	var publishErr *PublishError
+	if errors.As(err, &publishErr) {
+		t.Errorf("expected the first error only, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "broken one") {
+		t.Errorf("expected error of the first broken case, got %v", err)
+	}
+
+	suites := rp.children("")
+	if len(suites) != 1 {
+		t.Fatalf("expected 1 suite, got %d", len(suites))
+	}
+	if cases := rp.children(suites[0].ID); len(cases) != 1 || cases[0].Name != "first" {
+		t.Errorf("expected publishing to stop after the first case, got %+v", cases)
+	}
+	for _, id := range []string{"launch", suites[0].ID} {
+		if status, _ := rp.finishedStatus(id); status != string(ExecutionStatusFailed) {
+			t.Errorf("expected %s to be finished as failed, got %q", id, status)
+		}
+	}
+}
+
+func TestPublishFlattenSingleSuite(t *testing.T) {
+	report, err := LoadXMLReport("testdata/ordinal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parents := make(map[bool]string)
+	for _, flatten := range []bool{false, true} {
+		rp := newFakeRP(t)
+		_, err = rp.client().Publish(report, &Launch{Name: "flatten"}, WithFlattenSingleSuite(flatten))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var steps []fakeItem
+		for _, item := range rp.items {
+			if item.Type == string(TestItemTypeStep) {
+				steps = append(steps, item)
+			}
+		}
+		if len(steps) != report.TesCaseCount(0) {
+			t.Fatalf("flatten %t: expected %d steps, got %+v", flatten, report.TesCaseCount(0), rp.items)
+		}
+		parents[flatten] = steps[0].ParentID
+		wantSuites := 1
+		if flatten {
+			wantSuites = 0
+		}
+		if n := len(rp.items) - len(steps); n != wantSuites {
+			t.Errorf("flatten %t: expected %d suites, got %d", flatten, wantSuites, n)
+		}
+	}
+	if parents[false] == parents[true] {
+		t.Errorf("expected different parents of steps, got %q for both", parents[false])
+	}
+	if parents[true] != "" {
+		t.Errorf("expected flattened steps directly under the launch, got parent %q", parents[true])
+	}
+}
+
+func TestPublishLaunchStartedCallback(t *testing.T) {
+	report, err := LoadXMLReport("testdata/ordinal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	var calls []string
+	callback := func(launchID string) {
+		rp.mu.Lock()
+		defer rp.mu.Unlock()
+		calls = append(calls, fmt.Sprintf("%s with %d items", launchID, len(rp.items)))
+	}
+	_, err = rp.client().Publish(report, &Launch{Name: "callback"}, WithLaunchStartedCallback(callback))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"launch with 0 items"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected callback %v before items are started, got %v", want, calls)
+	}
+	if len(rp.items) == 0 {
+		t.Error("expected items started after callback")
+	}
+}
+
+func TestPublishAttributeFromEnv(t *testing.T) {
+	report, err := LoadXMLReport("testdata/ordinal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_GIT_BRANCH", "main")
+	t.Setenv("TEST_BUILD_NUMBER", "42")
+	t.Setenv("TEST_EMPTY", "")
+	rp := newFakeRP(t)
+	_, err = rp.client().Publish(report, &Launch{Name: "env", Tags: []string{"nightly"}}, WithAttributeFromEnv(map[string]string{
+		"branch": "TEST_GIT_BRANCH",
+		"build":  "TEST_BUILD_NUMBER",
+		"empty":  "TEST_EMPTY",
+		"unset":  "TEST_UNSET",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rp.launches) != 1 {
+		t.Fatalf("expected 1 launch, got %d", len(rp.launches))
+	}
+	if want := []string{"nightly", "branch:main", "build:42"}; !reflect.DeepEqual(rp.launches[0].Tags, want) {
+		t.Errorf("expected launch tags %v, got %v", want, rp.launches[0].Tags)
+	}
+}
+
+func TestPublishSuiteOverheadLog(t *testing.T) {
+	for _, threshold := range []time.Duration{time.Second, 10 * time.Second} {
+		report, err := LoadXMLReport("testdata/overhead", WithSuiteOverheadThreshold(threshold))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rp := newFakeRP(t)
+		if _, err := rp.client().Publish(report, &Launch{Name: "overhead"}); err != nil {
+			t.Fatal(err)
+		}
+		suites := rp.children("")
+		if len(suites) != 1 {
+			t.Fatalf("expected 1 suite, got %d", len(suites))
+		}
+
+		messages := rp.itemLogs(suites[0].ID)
+		var want []string
+		if threshold < 8*time.Second {
+			want = []string{"setup/teardown overhead: 8s"}
+		}
+		if !reflect.DeepEqual(messages, want) {
+			t.Errorf("threshold %s: expected suite logs %q, got %q", threshold, want, messages)
+		}
+	}
+}
+
+func TestPublishLaunchNameTemplate(t *testing.T) {
+	report, err := LoadXMLReport("testdata/ordinal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_PIPELINE", "nightly")
+	rp := newFakeRP(t)
+	_, err = rp.client().Publish(report, &Launch{Name: "unused"}, WithLaunchName("{env.TEST_PIPELINE} {date} ({count} suites) {unknown}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// date is launch start date, which is report start date
+	want := "nightly 2017-05-05 (1 suites) {unknown}"
+	if len(rp.launches) != 1 || rp.launches[0].Name != want {
+		t.Errorf("expected launch name %q, got %+v", want, rp.launches)
+	}
+}
+
+func TestPublishRootProperties(t *testing.T) {
+	report, err := LoadXMLReport("testdata/rootprops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// property of later file overrides the same property of previous one, suite properties are not included
+	want := map[string]string{"env": "staging", "browser": "firefox"}
+	if attributes := report.LaunchAttributes(); !reflect.DeepEqual(attributes, want) {
+		t.Errorf("expected launch attributes %v, got %v", want, attributes)
+	}
+
+	rp := newFakeRP(t)
+	if _, err := rp.client().Publish(report, &Launch{Name: "properties"}); err != nil {
+		t.Fatal(err)
+	}
+	if tags := []string{"browser:firefox", "env:staging"}; len(rp.launches) != 1 || !reflect.DeepEqual(rp.launches[0].Tags, tags) {
+		t.Errorf("expected launch tags %v, got %+v", tags, rp.launches)
+	}
+}
+
+func TestPublishKeepsCallerLaunch(t *testing.T) {
+	report, err := LoadXMLReport("testdata/rootprops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	launch := &Launch{Name: "reused", Tags: []string{"ci"}}
+	for k := 0; k < 2; k++ {
+		if _, err := rp.client().Publish(report, launch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(launch, &Launch{Name: "reused", Tags: []string{"ci"}}) {
+		t.Errorf("expected caller launch unchanged, got %+v", launch)
+	}
+	tags := []string{"ci", "browser:firefox", "env:staging"}
+	for k, published := range rp.launches {
+		if !reflect.DeepEqual(published.Tags, tags) {
+			t.Errorf("launch %d: expected tags %v, got %v", k, tags, published.Tags)
+		}
+	}
+}
+
+func TestPublishItemAttributeMapper(t *testing.T) {
+	report, err := LoadXMLReport("testdata/smoke")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper := func(item *TestItem) map[string]string {
+		if item.Type == TestItemTypeStep && strings.Contains(strings.ToLower(item.Name), "smoke") {
+			return map[string]string{"smoke": "", "tier": "1"}
+		}
+		return nil
+	}
+	rp := newFakeRP(t)
+	if _, err := rp.client().Publish(report, &Launch{Name: "mapper"}, WithItemAttributeMapper(mapper)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"s.Checkout":         nil,
+		"smokeCheckout":      {"smoke", "tier:1"},
+		"checkoutWithCoupon": nil,
+	}
+	if len(rp.items) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(rp.items))
+	}
+	for _, item := range rp.items {
+		if !reflect.DeepEqual(item.Tags, want[item.Name]) {
+			t.Errorf("item %s: expected tags %v, got %v", item.Name, want[item.Name], item.Tags)
+		}
+	}
+}
+
+func TestPublishCodeRef(t *testing.T) {
+	for _, codeRef := range []bool{false, true} {
+		report, err := LoadXMLReport("testdata/smoke", WithCodeRef(codeRef))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rp := newFakeRP(t)
+		if _, err := rp.client().Publish(report, &Launch{Name: "code ref"}); err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{"s.Checkout": ""}
+		if codeRef {
+			want["smokeCheckout"] = "s.Checkout.smokeCheckout"
+			want["checkoutWithCoupon"] = "s.Checkout.checkoutWithCoupon"
+		}
+		for _, item := range rp.items {
+			if item.CodeRef != want[item.Name] {
+				t.Errorf("code ref %t: expected %s code ref %q, got %q", codeRef, item.Name, want[item.Name], item.CodeRef)
+			}
+		}
+	}
+}
+
+func TestPublishOnCaseFailed(t *testing.T) {
+	report, err := LoadXMLReport("testdata/transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failed []string
+	callback := func(suiteName, caseName string, failure *LogMessage) {
+		failed = append(failed, suiteName+"/"+caseName+": "+failure.Message)
+	}
+	rp := newFakeRP(t)
+	if _, err := rp.client().Publish(report, &Launch{Name: "failed"}, WithOnCaseFailed(callback)); err != nil {
+		t.Fatal(err)
+	}
+	// errored case is failed as well
+	if want := []string{"t.Api/get[2]: not found", "t.Ui/click: boom"}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("expected callbacks %q, got %q", want, failed)
+	}
+}
+
+func TestPublishAssertionsAttribute(t *testing.T) {
+	report, err := LoadXMLReport("testdata/assertions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// case without assertions attribute counts as zero
+	if total := report.TotalAssertions(); total != 7 {
+		t.Errorf("expected 7 assertions, got %d", total)
+	}
+
+	rp := newFakeRP(t)
+	if _, err := rp.client().Publish(report, &Launch{Name: "assertions"}, WithAssertionsAttribute(true)); err != nil {
+		t.Fatal(err)
+	}
+	if tags := []string{"assertions:7"}; len(rp.launches) != 1 || !reflect.DeepEqual(rp.launches[0].Tags, tags) {
+		t.Errorf("expected launch tags %v, got %+v", tags, rp.launches)
+	}
+}
+
+func TestPublishLogPolicy(t *testing.T) {
+	report, err := LoadXMLReport("testdata/logpolicy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		policy LogPolicy
+		want   map[string][]string
+	}{
+		{LogPolicyFailuresOnly, map[string][]string{"failed": {"failed output", "expected"}}},
+		{LogPolicyAllCases, map[string][]string{"failed": {"failed output", "expected"}, "passed": {"passed output"}}},
+	}
+	for _, test := range tests {
+		rp := newFakeRP(t)
+		if _, err := rp.client().Publish(report, &Launch{Name: "policy"}, WithLogPolicy(test.policy)); err != nil {
+			t.Fatal(err)
+		}
+		suites := rp.children("")
+		if len(suites) != 1 {
+			t.Fatalf("expected 1 suite, got %d", len(suites))
+		}
+		for _, tCase := range rp.children(suites[0].ID) {
+			if messages := rp.itemLogs(tCase.ID); !reflect.DeepEqual(messages, test.want[tCase.Name]) {
+				t.Errorf("policy %d: expected %s logs %q, got %q", test.policy, tCase.Name, test.want[tCase.Name], messages)
+			}
+		}
+	}
+}
+
+func TestPublishGroupAttributes(t *testing.T) {
+	report, err := LoadXMLReport("testdata/groups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	if _, err := rp.client().Publish(report, &Launch{Name: "groups"}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"g.Tagged": nil, "case": {"group:smoke", "group:regression"}}
+	if len(rp.items) != len(want) {
+		t.Fatalf("expected %d items, got %+v", len(want), rp.items)
+	}
+	for _, item := range rp.items {
+		if !reflect.DeepEqual(item.Tags, want[item.Name]) {
+			t.Errorf("expected %s tags %q, got %q", item.Name, want[item.Name], item.Tags)
+		}
+	}
+}
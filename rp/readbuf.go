@@ -0,0 +1,85 @@
+package rp
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// readBuffers are reused for reading report files, so buffer growth is not repeated for every file
+var readBuffers = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// defaultPooledBufferLimit is capacity of the largest read buffer returned to the pool without WithReadBufferSize
+const defaultPooledBufferLimit = 4 << 20
+
+// readAll reads r into pooled buffer grown to at least size hint or read buffer size. Buffer content is valid
+// until the buffer is released with releaseBuffer, so it should be copied to be kept
+func (report *XMLReport) readAll(r io.Reader, sizeHint int) (*bytes.Buffer, error) {
+	buf := readBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	if sizeHint < report.readBufferSize {
+		sizeHint = report.readBufferSize
+	}
+	// bytes.Buffer.ReadFrom needs extra space to detect EOF without growing
+	buf.Grow(sizeHint + bytes.MinRead)
+	_, err := buf.ReadFrom(r)
+	if err != nil {
+		report.releaseBuffer(buf)
+		return nil, err
+	}
+	return buf, nil
+}
+
+// releaseBuffer returns read buffer to the pool, buffer grown over pooled buffer limit is left to garbage collector
+// so single huge report file does not keep its buffer for the next ones
+func (report *XMLReport) releaseBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= report.pooledBufferLimit() {
+		readBuffers.Put(buf)
+	}
+}
+
+// pooledBufferLimit is capacity of the largest read buffer returned to the pool, 4 times read buffer size
+// set by WithReadBufferSize
+func (report *XMLReport) pooledBufferLimit() int {
+	if report.readBufferSize > 0 {
+		return 4 * report.readBufferSize
+	}
+	return defaultPooledBufferLimit
+}
+
+// decodeFile reads report file with pooled buffer sized by file size and decodes it
+func (report *XMLReport) decodeFile(path string, decode reportFileDecoder) (*xmlReportFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var size int
+	if info, err := f.Stat(); err == nil {
+		size = int(info.Size())
+	}
+	return report.decodeReader(f, size, decode)
+}
+
+// decodeReader decodes report file straight from pooled buffer, file content is copied only when kept by WithRawXML
+func (report *XMLReport) decodeReader(r io.Reader, sizeHint int, decode reportFileDecoder) (*xmlReportFile, error) {
+	buf, err := report.readAll(r, sizeHint)
+	if err != nil {
+		return nil, err
+	}
+	defer report.releaseBuffer(buf)
+
+	reportFile, err := decode(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	report.setRawXML(reportFile, buf.Bytes())
+	return reportFile, nil
+}
@@ -0,0 +1,94 @@
+package rp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLargeSuiteFiles writes n report files of suites with 500 cases each
+func writeLargeSuiteFiles(tb testing.TB, dir string, n int) {
+	for k := 0; k < n; k++ {
+		err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("TEST-%d.xml", k)), []byte(largeSuite(500)), 0644)
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func TestRawXMLCopiedFromPooledBuffer(t *testing.T) {
+	opts := []ReportOption{WithReadBufferSize(64), WithRawXML(true)}
+	first, err := DecodeSuite(strings.NewReader(`<testsuite name="First" tests="0"/>`), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := DecodeSuite(strings.NewReader(`<testsuite name="Second" tests="0"/>`), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw := string(first.SuiteRawXML(0)); raw != `<testsuite name="First" tests="0"/>` {
+		t.Errorf("expected raw xml kept after buffer reuse, got %q", raw)
+	}
+	if raw := string(second.SuiteRawXML(0)); raw != `<testsuite name="Second" tests="0"/>` {
+		t.Errorf("expected raw xml of second suite, got %q", raw)
+	}
+}
+
+func TestPooledBufferLimit(t *testing.T) {
+	if limit := newXMLReport(nil).pooledBufferLimit(); limit != defaultPooledBufferLimit {
+		t.Errorf("expected default limit %d, got %d", defaultPooledBufferLimit, limit)
+	}
+	report := newXMLReport([]ReportOption{WithReadBufferSize(64)})
+	if limit := report.pooledBufferLimit(); limit != 256 {
+		t.Errorf("expected limit of 4 read buffer sizes, got %d", limit)
+	}
+}
+
+// BenchmarkReadFile compares allocations per file of pooled buffer and plain io.ReadAll
+func BenchmarkReadFile(b *testing.B) {
+	const files = 20
+	dir := b.TempDir()
+	writeLargeSuiteFiles(b, dir, files)
+	paths, err := filepath.Glob(filepath.Join(dir, "*.xml"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("io.ReadAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, path := range paths {
+				f, err := os.Open(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				_, err = io.ReadAll(f)
+				f.Close()
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		report := newXMLReport(nil)
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, path := range paths {
+				f, err := os.Open(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				buf, err := report.readAll(f, 0)
+				f.Close()
+				if err != nil {
+					b.Fatal(err)
+				}
+				report.releaseBuffer(buf)
+			}
+		}
+	})
+}
@@ -1,13 +1,17 @@
 package rp
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,15 +19,54 @@ import (
 // XMLReport identifies JUnit XML format specification that Hudson supports
 type XMLReport struct {
 	xmlSuites []xmlSuite
+
+	classNameNormalizer func(string) string
+	modTimeFallback     bool
+	maxDuration         time.Duration
+	mergeSplitSuites    bool
+	failureTypeLevels   map[string]LogLevel
+	overheadThreshold   time.Duration
+	codeRef             bool
+	startFromFirstCase  bool
+	systemErrFailures   bool
+	defaultPackage      string
+	partialWriteWindow  time.Duration
+	partialWriteDelay   time.Duration
+	minDuration         time.Duration
+	readBufferSize      int
+	reconcileCounters   bool
+	startClamp          bool
+	suiteNamePrefix     string
+	suiteNameSuffix     string
+	suiteOrder          SuiteOrder
+	noSort              bool
+	chunkWindow         int
+	rawXML              bool
+
+	launchAttributes map[string]string
+	// timestamps are parsed timestamps shared with reports derived from this one
+	timestamps *timestampCache
 }
 
+// SuiteOrder is order of report top level suites
+type SuiteOrder int
+
+const (
+	// SuiteOrderByTimestamp orders suites by start time
+	SuiteOrderByTimestamp SuiteOrder = iota
+	// SuiteOrderByID orders suites by id attribute
+	SuiteOrderByID
+	// SuiteOrderByName orders suites by package and name
+	SuiteOrderByName
+)
+
 type xmlSuite struct {
 	XMLName     string        `xml:"testsuite"`
 	ID          int           `xml:"id,attr"`
 	Name        string        `xml:"name,attr"`
 	PackageName string        `xml:"package,attr"`
 	TimeStamp   string        `xml:"timestamp,attr"`
-	Time        float64       `xml:"time,attr"`
+	Time        xmlSeconds    `xml:"time,attr"`
 	HostName    string        `xml:"hostname,attr"`
 	Tests       int           `xml:"tests,attr"`
 	Failures    int           `xml:"failures,attr"`
@@ -31,19 +74,119 @@ type xmlSuite struct {
 	Skipped     int           `xml:"skipped,attr"`
 	Properties  xmlProperties `xml:"properties"`
 	Cases       []xmlTest     `xml:"testcase"`
+	Suites      []xmlSuite    `xml:"testsuite"`
 	SystemOut   string        `xml:"system-out"`
 	SystemErr   string        `xml:"system-err"`
+
+	// parent is parent suite index plus one, 0 for top level suite
+	parent int
+	// raw is XML of top level suite kept with WithRawXML, see setRawXML
+	raw []byte
+	// source is base name of the report file suite is decoded from
+	source string
+}
+
+// xmlSeconds is xml time attribute in seconds, comma decimal separator used by some locales is accepted
+type xmlSeconds float64
+
+// UnmarshalXMLAttr parses seconds with dot or comma decimal separator, or HH:MM:SS(.fff) time
+func (s *xmlSeconds) UnmarshalXMLAttr(attr xml.Attr) error {
+	value := strings.Replace(strings.TrimSpace(attr.Value), ",", ".", 1)
+	if len(value) == 0 {
+		*s = 0
+		return nil
+	}
+	if strings.Contains(value, ":") {
+		return s.parseClock(value)
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("invalid time '%s'", value)
+	}
+	*s = xmlSeconds(f)
+	return nil
+}
+
+// parseClock parses HH:MM:SS(.fff) or MM:SS(.fff) time as seconds
+func (s *xmlSeconds) parseClock(value string) error {
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return fmt.Errorf("invalid time '%s'", value)
+	}
+	var seconds float64
+	for k, part := range parts {
+		var f float64
+		var err error
+		if k == len(parts)-1 {
+			f, err = strconv.ParseFloat(part, 64)
+		} else {
+			var n int
+			n, err = strconv.Atoi(part)
+			f = float64(n)
+		}
+		if err != nil || f < 0 {
+			return fmt.Errorf("invalid time '%s'", value)
+		}
+		seconds = seconds*60 + f
+	}
+	*s = xmlSeconds(seconds)
+	return nil
+}
+
+// duration converts seconds to time.Duration
+func (s xmlSeconds) duration() time.Duration {
+	return secondsToDuration(float64(s))
+}
+
+// xmlAggregate is <testsuites> document aggregating several suites e.g. generated by Surefire
+type xmlAggregate struct {
+	XMLName    string        `xml:"testsuites"`
+	Properties xmlProperties `xml:"properties"`
+	Suites     []xmlSuite    `xml:"testsuite"`
+}
+
+// xmlReportFile holds suites decoded from single report file
+type xmlReportFile struct {
+	path       string
+	aggregate  bool
+	properties []xmlProperty
+	suites     []xmlSuite
 }
 
 type xmlProperties struct {
+	Properties []xmlProperty `xml:"property"`
+}
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
 }
 
 type xmlTest struct {
-	Name      string      `xml:"name,attr"`
-	ClassName string      `xml:"classname,attr"`
-	Time      float64     `xml:"time,attr"`
-	Failure   *xmlFailure `xml:"failure,omitempty"`
-	Skipped   *xmlSkipped `xml:"skipped,omitempty"`
+	Name        string      `xml:"name,attr"`
+	ClassName   string      `xml:"classname,attr"`
+	Description string      `xml:"description,attr"`
+	TimeStamp   string      `xml:"timestamp,attr"`
+	Time        xmlSeconds  `xml:"time,attr"`
+	Assertions  int         `xml:"assertions,attr"`
+	Failure     *xmlFailure `xml:"failure,omitempty"`
+	Error       *xmlFailure `xml:"error,omitempty"`
+	Skipped     *xmlSkipped `xml:"skipped,omitempty"`
+	Disabled    *xmlSkipped `xml:"disabled,omitempty"`
+	Status      string      `xml:"status,attr"`
+	Group       string      `xml:"group,attr"`
+	SystemOut   string      `xml:"system-out"`
+	SystemErr   string      `xml:"system-err"`
+
+	// ordinal is test case position in the source suite document
+	ordinal int
+	// offset is test case start relative to suite start, sum of durations of previous cases in the suite
+	offset time.Duration
+	// tags of test case from formats supporting them
+	tags []string
 }
 
 type xmlFailure struct {
@@ -57,14 +200,190 @@ type xmlSkipped struct {
 }
 
 // LoadXMLReport is used for loading JUnit XML report from specified directory
-func LoadXMLReport(dirName string) (*XMLReport, error) {
-	report, err := parseXMLReport(dirName)
+func LoadXMLReport(dirName string, opts ...ReportOption) (*XMLReport, error) {
+	return LoadXMLReportContext(context.Background(), dirName, opts...)
+}
+
+// LoadXMLReportContext is used for loading JUnit XML report from specified directory,
+// loading is aborted with ctx.Err() as soon as ctx is done
+func LoadXMLReportContext(ctx context.Context, dirName string, opts ...ReportOption) (*XMLReport, error) {
+	report := newXMLReport(opts)
+	err := report.parseXMLReport(ctx, dirName, isXMLFile, decodeXMLReportFile)
 	if err != nil {
 		return nil, err
 	}
-	return &XMLReport{
-		xmlSuites: report,
-	}, nil
+	return report, nil
+}
+
+// LoadXMLReportMatch is used for loading JUnit XML report from specified directory files
+// which names are accepted by match, e.g. to exclude non report xml files
+func LoadXMLReportMatch(dirName string, match func(name string) bool, opts ...ReportOption) (*XMLReport, error) {
+	report := newXMLReport(opts)
+	err := report.parseXMLReport(context.Background(), dirName, match, decodeXMLReportFile)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// LoadXMLReportSince is used for loading JUnit XML report from specified directory skipping suites
+// started before since, e.g. stale reports of previous runs. Suites without timestamp are skipped
+// unless WithModTimeFallback is enabled
+func LoadXMLReportSince(dirName string, since time.Time, opts ...ReportOption) (*XMLReport, error) {
+	report := newXMLReport(opts)
+	reportFiles, err := report.parseXMLReportFiles(context.Background(), dirName, isXMLFile, decodeXMLReportFile)
+	if err != nil {
+		return nil, err
+	}
+
+	xSuites := make([]xmlSuite, 0)
+	for _, reportFile := range reportFiles {
+		for _, xSuite := range reportFile.suites {
+			if len(xSuite.TimeStamp) == 0 || report.timeStamp(xSuite.TimeStamp).Before(since) {
+				continue
+			}
+			xSuites = append(xSuites, xSuite)
+		}
+	}
+	report.setSuites(xSuites)
+	return report, nil
+}
+
+// DecodeSuite is used for decoding report from single <testsuite> or <testsuites> document,
+// e.g. for custom report files discovery
+func DecodeSuite(r io.Reader, opts ...ReportOption) (*XMLReport, error) {
+	report := newXMLReport(opts)
+	reportFile, err := report.decodeReader(r, 0, decodeXMLReportFile)
+	if err != nil {
+		return nil, err
+	}
+	report.finishSuites(reportFile)
+	report.setSuites(reportFile.suites)
+	return report, nil
+}
+
+// LoadXMLReportFromReader is used for loading report from single <testsuite> or <testsuites> document read from r,
+// e.g. aggregate report piped by CI
+func LoadXMLReportFromReader(r io.Reader, opts ...ReportOption) (*XMLReport, error) {
+	return DecodeSuite(r, opts...)
+}
+
+// LoadXMLReportStdin is used for loading report from single <testsuite> or <testsuites> document read from stdin
+func LoadXMLReportStdin(opts ...ReportOption) (*XMLReport, error) {
+	return LoadXMLReportFromReader(os.Stdin, opts...)
+}
+
+// LoadXMLReportDeaggregate is used for loading JUnit XML report from directory which contains both
+// <testsuites> aggregate and per-module <testsuite> files, suites already present in aggregate
+// (matched by suite name) are skipped so they are not reported twice
+func LoadXMLReportDeaggregate(dirName string, opts ...ReportOption) (*XMLReport, error) {
+	report := newXMLReport(opts)
+	reportFiles, err := report.parseXMLReportFiles(context.Background(), dirName, isXMLFile, decodeXMLReportFile)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregated := make(map[string]bool)
+	for _, reportFile := range reportFiles {
+		if !reportFile.aggregate {
+			continue
+		}
+		for _, xSuite := range reportFile.suites {
+			aggregated[xSuite.Name] = true
+		}
+	}
+
+	xSuites := make([]xmlSuite, 0)
+	for _, reportFile := range reportFiles {
+		for _, xSuite := range reportFile.suites {
+			if !reportFile.aggregate && aggregated[xSuite.Name] {
+				log.Debugf("suite '%s' from '%s' is already aggregated", xSuite.Name, reportFile.path)
+				continue
+			}
+			xSuites = append(xSuites, xSuite)
+		}
+	}
+	report.setSuites(xSuites)
+	return report, nil
+}
+
+// MustLoadXMLReport is like LoadXMLReport but panics if the report could not be loaded.
+// It is intended for package initialization and tests only, do not use it in production flows
+func MustLoadXMLReport(dirName string, opts ...ReportOption) *XMLReport {
+	report, err := LoadXMLReport(dirName, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("rp: MustLoadXMLReport(%q): %v", dirName, err))
+	}
+	return report
+}
+
+// RegroupByClassName creates new report where cases of every suite are redistributed into synthetic suites
+// keyed by case class name, e.g. for tools emitting single flat suite. Each synthetic suite starts
+// at its first case start time and lasts the sum of its case times, cases without class name keep suite name
+func (report *XMLReport) RegroupByClassName() *XMLReport {
+	xSuites := make([]xmlSuite, 0, len(report.xmlSuites))
+	for i, xSuite := range report.xmlSuites {
+		groups := make(map[string]int)
+		for j, xCase := range xSuite.Cases {
+			k, ok := groups[xCase.ClassName]
+			if !ok {
+				k = len(xSuites)
+				groups[xCase.ClassName] = k
+				xSuites = append(xSuites, classNameSuite(xSuite, xCase.ClassName, report.TestCaseStartTime(i, j)))
+			}
+			xGroup := &xSuites[k]
+			xGroup.Cases = append(xGroup.Cases, xCase)
+			xGroup.Tests++
+			xGroup.Time += xCase.Time
+			if xCase.Failure != nil {
+				xGroup.Failures++
+			}
+			if xCase.Error != nil {
+				xGroup.Errors++
+			}
+			if xCase.Skipped != nil {
+				xGroup.Skipped++
+			}
+		}
+	}
+	report.sortSuites(xSuites)
+	report.setCaseOffsets(xSuites)
+
+	regrouped := *report
+	regrouped.xmlSuites = xSuites
+	return &regrouped
+}
+
+// classNameSuite creates empty synthetic suite for class name cases of given suite
+func classNameSuite(xSuite xmlSuite, className string, start time.Time) xmlSuite {
+	packageName, name := xSuite.PackageName, xSuite.Name
+	if len(className) != 0 {
+		packageName, name = "", className
+		if k := strings.LastIndex(className, "."); k >= 0 {
+			packageName, name = className[:k], className[k+1:]
+		}
+	}
+	return xmlSuite{
+		ID:          xSuite.ID,
+		Name:        name,
+		PackageName: packageName,
+		TimeStamp:   start.Format(TimestampLayout),
+		HostName:    xSuite.HostName,
+		Properties:  xSuite.Properties,
+	}
+}
+
+// newXMLReport creates empty report configured with given options
+func newXMLReport(opts []ReportOption) *XMLReport {
+	report := &XMLReport{
+		classNameNormalizer: func(className string) string { return className },
+		timestamps:          newTimestampCache(),
+		startClamp:          true,
+	}
+	for _, opt := range opts {
+		opt(report)
+	}
+	return report
 }
 
 // SuitesCount provides suite count for current xml test result report
@@ -79,41 +398,106 @@ func (report *XMLReport) TesCaseCount(i int) int {
 
 // LaunchStartTime is used to calc launch time, it will be equal to 0 suite start time
 func (report *XMLReport) LaunchStartTime() time.Time {
-	return parseTimeStamp(report.xmlSuites[0].TimeStamp)
+	if len(report.xmlSuites) == 0 {
+		return time.Time{}
+	}
+	return report.timeStamp(report.xmlSuites[0].TimeStamp)
 }
 
-// LaunchEndTime is used to calc launch end time, it will be equal to last suite start time plus last suite duration
+// LaunchEndTime is used to calc launch end time, it will be equal to last top level suite start time plus its duration
 func (report *XMLReport) LaunchEndTime() time.Time {
+	if len(report.xmlSuites) == 0 {
+		return time.Time{}
+	}
 	lastIndex := len(report.xmlSuites) - 1
-	lastSuiteStart := parseTimeStamp(report.xmlSuites[lastIndex].TimeStamp)
-	d := secondsToDuration(report.xmlSuites[lastIndex].Time)
+	for report.xmlSuites[lastIndex].parent != 0 {
+		lastIndex--
+	}
+	lastSuiteStart := report.timeStamp(report.xmlSuites[lastIndex].TimeStamp)
+	d := report.xmlSuites[lastIndex].Time.duration()
 	return lastSuiteStart.Add(d)
 }
 
-// Suite is used ot create new TestItem type SUITE for xml suite
+// LaunchDuration is time between launch start and launch end
+func (report *XMLReport) LaunchDuration() time.Duration {
+	return report.LaunchEndTime().Sub(report.LaunchStartTime())
+}
+
+// DefaultDescription provides launch description based on report stats e.g. '42 tests, 3 failed, 1 errored, 2 skipped in 1m12s'
+func (report *XMLReport) DefaultDescription() string {
+	summary := report.Summary()
+	return fmt.Sprintf("%d tests, %d failed, %d errored, %d skipped in %s",
+		summary.Tests, summary.Failures, summary.Errors, summary.Skipped, report.LaunchDuration().Round(time.Second))
+}
+
+// SuiteFailureCount provides count of failed test cases declared by suite, errors are not included
+func (report *XMLReport) SuiteFailureCount(i int) int {
+	return report.xmlSuites[i].Failures
+}
+
+// SuiteErrorCount provides count of errored test cases declared by suite
+func (report *XMLReport) SuiteErrorCount(i int) int {
+	return report.xmlSuites[i].Errors
+}
+
+// LaunchAttributes provides properties of root <testsuites> elements shared by all report suites
+func (report *XMLReport) LaunchAttributes() map[string]string {
+	attributes := make(map[string]string, len(report.launchAttributes))
+	for key, value := range report.launchAttributes {
+		attributes[key] = value
+	}
+	return attributes
+}
+
+// SuiteParent provides index of parent suite for nested suite, -1 for top level suite
+func (report *XMLReport) SuiteParent(i int) int {
+	return report.xmlSuites[i].parent - 1
+}
+
+// SuiteChildren provides indexes of suites nested directly into given suite
+func (report *XMLReport) SuiteChildren(i int) []int {
+	children := make([]int, 0)
+	for k := i + 1; k < len(report.xmlSuites); k++ {
+		if report.xmlSuites[k].parent == i+1 {
+			children = append(children, k)
+		}
+	}
+	return children
+}
+
+// Suite is used ot create new TestItem type SUITE for xml suite, every call provides new TestItem
+// which could be changed by caller without affecting the report
 func (report *XMLReport) Suite(i int) *TestItem {
 	xSuite := report.xmlSuites[i]
-	suiteStart := parseTimeStamp(xSuite.TimeStamp)
-	xSuiteNames := []string{xSuite.PackageName, xSuite.Name}
+	suiteStart := report.suiteStart(i)
+	description := fmt.Sprintf("%s %d", TestItemTypeSuite, xSuite.ID)
+
+	// skip empty package or name, suite without both is named by report file
+	packageName := xSuite.PackageName
+	if len(packageName) == 0 && len(xSuite.Name) != 0 {
+		packageName = report.defaultPackage
+	}
+	name := strings.Trim(packageName+"."+xSuite.Name, ".")
+	if len(name) == 0 {
+		name = strings.TrimSuffix(xSuite.source, filepath.Ext(xSuite.source))
+	}
+	if len(name) == 0 {
+		name = description
+	}
+	name = report.suiteNamePrefix + name + report.suiteNameSuffix
 
 	return &TestItem{
 		Type:        TestItemTypeSuite,
 		StartTime:   suiteStart,
-		Name:        strings.Join(xSuiteNames, "."),
-		Description: fmt.Sprintf("%s %d", TestItemTypeSuite, xSuite.ID),
+		Name:        name,
+		Description: description,
 	}
 }
 
 // SuiteResult is used ot create new ExecutionResult for xml suite
 func (report *XMLReport) SuiteResult(i int) *ExecutionResult {
 	xSuite := report.xmlSuites[i]
-	suiteStart := parseTimeStamp(xSuite.TimeStamp)
-	t := xSuite.Time
-	if t <= 0 {
-		t = 00.1
-	}
-	d := secondsToDuration(t)
-	suiteEnd := suiteStart.Add(d)
+	suiteEnd := report.suiteEnd(i)
 
 	status := ExecutionStatusPassed
 	if xSuite.Tests == 0 {
@@ -122,6 +506,11 @@ func (report *XMLReport) SuiteResult(i int) *ExecutionResult {
 		status = ExecutionStatusFailed
 	} else if xSuite.Errors > 0 {
 		status = ExecutionStatusFailed
+	} else if allCasesSkipped(xSuite) {
+		status = ExecutionStatusSkipped
+	}
+	if report.reconcileCounters {
+		status = report.reconciledStatus(i, status)
 	}
 
 	return &ExecutionResult{
@@ -130,31 +519,120 @@ func (report *XMLReport) SuiteResult(i int) *ExecutionResult {
 	}
 }
 
-// TestCase is used ot create new TestItem type STEP for xml test case
-func (report *XMLReport) TestCase(i, j int) *TestItem {
+// reconciledStatus provides suite status derived from its and nested suites test cases when suite failures or errors
+// attributes disagree with the test cases, otherwise status derived from attributes is kept
+func (report *XMLReport) reconciledStatus(i int, status ExecutionStatus) ExecutionStatus {
+	var tests, failures, errors, skipped int
+	// nested suites directly follow their parent suite
+	for k := i; k < len(report.xmlSuites) && (k == i || report.xmlSuites[k].parent > i); k++ {
+		for _, xCase := range report.xmlSuites[k].Cases {
+			tests++
+			if xCase.Failure != nil {
+				failures++
+			}
+			if xCase.Error != nil {
+				errors++
+			}
+			if xCase.Skipped != nil {
+				skipped++
+			}
+		}
+	}
+
 	xSuite := report.xmlSuites[i]
-	suiteStart := parseTimeStamp(xSuite.TimeStamp)
-	xCase := xSuite.Cases[j]
-	return &TestItem{
-		Type:      TestItemTypeStep,
-		Name:      xCase.Name,
-		StartTime: suiteStart, // FixMe
+	if xSuite.Failures == failures && xSuite.Errors == errors {
+		return status
+	}
+	log.Warningf("suite '%s' claims %d failures and %d errors, but has %d failed and %d errored test cases",
+		xSuite.Name, xSuite.Failures, xSuite.Errors, failures, errors)
+	switch {
+	case tests == 0:
+		return ExecutionStatusSkipped
+	case failures > 0 || errors > 0:
+		return ExecutionStatusFailed
+	case skipped == tests:
+		return ExecutionStatusSkipped
 	}
+	return ExecutionStatusPassed
 }
 
-// TestCaseResult is used ot create new ExecutionResult for xml test case
-func (report *XMLReport) TestCaseResult(i, j int) *ExecutionResult {
+// SuitePropertiesLog is used to create new LogMessage with suite properties as key=value lines, nil for suite without properties
+func (report *XMLReport) SuitePropertiesLog(i int) *LogMessage {
 	xSuite := report.xmlSuites[i]
-	suiteStart := parseTimeStamp(xSuite.TimeStamp)
-	xCase := xSuite.Cases[j]
-	t := xCase.Time
-	if t <= 0 {
-		t = 00.1
+	xProperties := xSuite.Properties.Properties
+	if len(xProperties) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(xProperties))
+	for _, xProperty := range xProperties {
+		lines = append(lines, xProperty.Name+"="+xProperty.Value)
+	}
+	return &LogMessage{
+		Time:    report.suiteStart(i),
+		Level:   LogLevelInfo,
+		Message: strings.Join(lines, "\n"),
 	}
-	d := secondsToDuration(t)
-	xCaseEnd := suiteStart.Add(d)
+}
+
+// SuiteOverheadLog is used to create new LogMessage with suite setup/teardown overhead, i.e. part of suite time
+// not spent in its test cases and nested suites. Nil unless overhead exceeds WithSuiteOverheadThreshold threshold
+func (report *XMLReport) SuiteOverheadLog(i int) *LogMessage {
+	if report.overheadThreshold <= 0 {
+		return nil
+	}
+	xSuite := report.xmlSuites[i]
+	overhead := xSuite.Time.duration()
+	for _, xCase := range xSuite.Cases {
+		overhead -= xCase.Time.duration()
+	}
+	for _, k := range report.SuiteChildren(i) {
+		overhead -= report.xmlSuites[k].Time.duration()
+	}
+	if overhead <= report.overheadThreshold {
+		return nil
+	}
+	return &LogMessage{
+		Time:    report.suiteStart(i),
+		Level:   LogLevelInfo,
+		Message: fmt.Sprintf("setup/teardown overhead: %s", overhead),
+	}
+}
+
+// SuiteRawXML provides XML of top level suite kept with WithRawXML: <testsuite> element of <testsuites> file,
+// otherwise content of the report file for the first suite of the file. It is nil for nested suite
+func (report *XMLReport) SuiteRawXML(i int) []byte {
+	return report.xmlSuites[i].raw
+}
+
+// TestCase is used ot create new TestItem type STEP for xml test case, every call provides new TestItem
+// with own copy of tags which could be changed by caller without affecting the report.
+// Comma separated groups of test case group attribute are added as 'group:<value>' tags
+func (report *XMLReport) TestCase(i, j int) *TestItem {
+	xCase := report.xmlSuites[i].Cases[j]
+	tCase := &TestItem{
+		Type:        TestItemTypeStep,
+		Name:        xCase.Name,
+		Description: xCase.Description,
+		StartTime:   report.TestCaseStartTime(i, j),
+		Tags:        append([]string(nil), xCase.tags...),
+	}
+	for _, group := range strings.Split(xCase.Group, ",") {
+		if group = strings.TrimSpace(group); len(group) != 0 {
+			tCase.Tags = append(tCase.Tags, "group:"+group)
+		}
+	}
+	if report.codeRef {
+		tCase.CodeRef = report.TestCaseFullName(i, j)
+	}
+	return tCase
+}
+
+// TestCaseResult is used ot create new ExecutionResult for xml test case
+func (report *XMLReport) TestCaseResult(i, j int) *ExecutionResult {
+	xCase := report.xmlSuites[i].Cases[j]
 	var status = ExecutionStatusPassed
-	if xCase.Failure != nil {
+	if xCase.Failure != nil || xCase.Error != nil {
 		status = ExecutionStatusFailed
 	}
 	if xCase.Skipped != nil {
@@ -162,52 +640,97 @@ func (report *XMLReport) TestCaseResult(i, j int) *ExecutionResult {
 	}
 
 	return &ExecutionResult{
-		EndTime: xCaseEnd,
+		EndTime: report.TestCaseEndTime(i, j),
 		Status:  status,
 	}
 }
 
+// TestCaseOrdinal provides position of test case in the source suite document
+func (report *XMLReport) TestCaseOrdinal(i, j int) int {
+	return report.xmlSuites[i].Cases[j].ordinal
+}
+
+// TestCaseClassName provides normalized class name for given xml suite and test case
+func (report *XMLReport) TestCaseClassName(i, j int) string {
+	return report.classNameNormalizer(report.xmlSuites[i].Cases[j].ClassName)
+}
+
+// TestCaseFullName provides normalized class name joined with test case name for given xml suite and test case
+func (report *XMLReport) TestCaseFullName(i, j int) string {
+	className := report.TestCaseClassName(i, j)
+	if len(className) == 0 {
+		return report.xmlSuites[i].Cases[j].Name
+	}
+	return className + "." + report.xmlSuites[i].Cases[j].Name
+}
+
 // HasTestCaseSkipped is used to check xml skipped value for given xml suite and test case
 func (report *XMLReport) HasTestCaseSkipped(i, j int) bool {
 	return report.xmlSuites[i].Cases[j].Skipped != nil
 }
 
+// TestCaseIsDisabled is used to check if test case is disabled, disabled test cases are reported as skipped
+func (report *XMLReport) TestCaseIsDisabled(i, j int) bool {
+	return isDisabled(report.xmlSuites[i].Cases[j])
+}
+
 // HasTestCaseFailure is used to check xml failure for given xml suite and test case
 func (report *XMLReport) HasTestCaseFailure(i, j int) bool {
 	return report.xmlSuites[i].Cases[j].Failure != nil
 }
 
-// TestCaseFailure is used to create new LogMessage with failure message for given xml suite and test case
+// TestCaseFailure is used to create new LogMessage with failure message for given xml suite and test case,
+// error message is used for test case with error and without failure. Message is empty for test case failed
+// by status attribute only
 func (report *XMLReport) TestCaseFailure(i, j int) *LogMessage {
-	xSuite := report.xmlSuites[i]
-	suiteStart := parseTimeStamp(xSuite.TimeStamp)
-	xCase := xSuite.Cases[j]
-	t := xCase.Time
-	if t <= 0 {
-		t = 00.1
+	xCase := report.xmlSuites[i].Cases[j]
+	xFailure := xCase.Failure
+	if xFailure == nil {
+		xFailure = xCase.Error
+	}
+	if xFailure == nil {
+		xFailure = &xmlFailure{}
 	}
-	d := secondsToDuration(t)
-	xCaseEnd := suiteStart.Add(d)
 	return &LogMessage{
-		Time:    xCaseEnd,
-		Level:   LogLevelError,
-		Message: xCase.Failure.Message,
+		Time:    report.TestCaseEndTime(i, j),
+		Level:   report.failureLevel(xFailure.Type),
+		Message: xFailure.Message,
+	}
+}
+
+// TestCaseFailureType provides failure type (e.g. exception class) for given xml suite and test case,
+// error type for test case with error and without failure, empty when absent
+func (report *XMLReport) TestCaseFailureType(i, j int) string {
+	xCase := report.xmlSuites[i].Cases[j]
+	if xCase.Failure != nil {
+		return xCase.Failure.Type
 	}
+	if xCase.Error != nil {
+		return xCase.Error.Type
+	}
+	return ""
+}
+
+// failureLevel provides log level mapped to failure type substring, the longest matching substring wins.
+// Log level defaults to error
+func (report *XMLReport) failureLevel(failureType string) LogLevel {
+	level, match := LogLevelError, ""
+	for substr, substrLevel := range report.failureTypeLevels {
+		if !strings.Contains(failureType, substr) {
+			continue
+		}
+		if len(substr) > len(match) || (len(substr) == len(match) && substr < match) {
+			level, match = substrLevel, substr
+		}
+	}
+	return level
 }
 
 // TesCaseSkippedMessage is used to create new Log Message with skiped message for given xml suite and test case
 func (report *XMLReport) TesCaseSkippedMessage(i, j int) *LogMessage {
-	xSuite := report.xmlSuites[i]
-	suiteStart := parseTimeStamp(xSuite.TimeStamp)
-	xCase := xSuite.Cases[j]
-	t := xCase.Time
-	if t <= 0 {
-		t = 00.1
-	}
-	d := secondsToDuration(t)
-	xCaseEnd := suiteStart.Add(d)
+	xCase := report.xmlSuites[i].Cases[j]
 	return &LogMessage{
-		Time:    xCaseEnd,
+		Time:    report.TestCaseEndTime(i, j),
 		Level:   LogLevelInfo,
 		Message: xCase.Skipped.Message,
 	}
@@ -215,69 +738,614 @@ func (report *XMLReport) TesCaseSkippedMessage(i, j int) *LogMessage {
 
 // TestCaseFailureDetails is used to create new LogMessage with failure details for given xml suite and test case
 func (report *XMLReport) TestCaseFailureDetails(i, j int) *LogMessage {
-	xSuite := report.xmlSuites[i]
-	suiteStart := parseTimeStamp(xSuite.TimeStamp)
-	xCase := xSuite.Cases[j]
-	d := secondsToDuration(xCase.Time)
-	xCaseEnd := suiteStart.Add(d)
+	xCase := report.xmlSuites[i].Cases[j]
 	return &LogMessage{
-		Time:    xCaseEnd,
+		Time:    report.TestCaseEndTime(i, j),
 		Level:   LogLevelInfo,
 		Message: xCase.Failure.Details,
 	}
 }
 
-// parseXMLReport is used for parsing xml report sorted by suite start time
-func parseXMLReport(reportDir string) ([]xmlSuite, error) {
+// TestCaseLogs is used to create all log messages of given xml suite and test case in chronological order:
+// system-out and system-err at test case start, then failure message and details, error message and details
+// and skipped message at test case end. Empty messages are omitted. Messages of class level entry, e.g. setup
+// failure, have class name item path, otherwise test case full name
+func (report *XMLReport) TestCaseLogs(i, j int) []*LogMessage {
+	xCase := report.xmlSuites[i].Cases[j]
+	startTime, endTime := report.TestCaseStartTime(i, j), report.TestCaseEndTime(i, j)
+
+	itemPath := report.TestCaseFullName(i, j)
+	if isClassLevelCase(xCase) {
+		itemPath = report.TestCaseClassName(i, j)
+	}
+	logs := make([]*LogMessage, 0)
+	add := func(t time.Time, level LogLevel, message string) {
+		if len(strings.TrimSpace(message)) != 0 {
+			logs = append(logs, &LogMessage{Time: t, Level: level, Message: message, ItemPath: itemPath})
+		}
+	}
+	add(startTime, LogLevelInfo, xCase.SystemOut)
+	add(startTime, LogLevelWarn, xCase.SystemErr)
+	if xCase.Failure != nil {
+		add(endTime, report.failureLevel(xCase.Failure.Type), xCase.Failure.Message)
+		add(endTime, LogLevelInfo, xCase.Failure.Details)
+	}
+	if xCase.Error != nil {
+		add(endTime, report.failureLevel(xCase.Error.Type), xCase.Error.Message)
+		add(endTime, LogLevelInfo, xCase.Error.Details)
+	}
+	if xCase.Skipped != nil {
+		add(endTime, LogLevelInfo, xCase.Skipped.Message)
+	}
+	return logs
+}
+
+// classLevelCaseNames are names of test case entries some runners write for failures of class setup or teardown,
+// e.g. Gradle 'classMethod'
+var classLevelCaseNames = map[string]bool{
+	"":            true,
+	"classMethod": true,
+}
+
+// isClassLevelCase checks whether test case entry stands for its class rather than single test
+func isClassLevelCase(xCase xmlTest) bool {
+	return classLevelCaseNames[xCase.Name] && len(xCase.ClassName) != 0
+}
+
+// TestCaseStartTime is test case timestamp if any, otherwise suite start time shifted by durations of all previous
+// cases in the suite. Report Portal orders items by start time, so the case index acts as ordinal even when
+// all cases share the same suite timestamp. Start time is clamped to suite span unless disabled by WithItemStartTimeClamp
+func (report *XMLReport) TestCaseStartTime(i, j int) time.Time {
+	xSuite := report.xmlSuites[i]
+	suiteStart := report.suiteStart(i)
+	start := suiteStart.Add(xSuite.Cases[j].offset)
+	if timeStamp := xSuite.Cases[j].TimeStamp; len(timeStamp) != 0 {
+		start = report.timeStamp(timeStamp)
+	}
+	if report.startClamp && !suiteStart.IsZero() {
+		start = clampTime(start, suiteStart, report.suiteSpanEnd(i))
+	}
+	return start
+}
+
+// suiteStart provides suite start time, nested suite start is clamped to parent suite span
+// unless disabled by WithItemStartTimeClamp
+func (report *XMLReport) suiteStart(i int) time.Time {
+	xSuite := report.xmlSuites[i]
+	start := report.timeStamp(xSuite.TimeStamp)
+	if report.startClamp && xSuite.parent != 0 {
+		parent := xSuite.parent - 1
+		if parentStart := report.suiteStart(parent); !parentStart.IsZero() {
+			start = clampTime(start, parentStart, report.suiteSpanEnd(parent))
+		}
+	}
+	return start
+}
+
+// suiteSpanEnd provides suite start time plus suite duration
+func (report *XMLReport) suiteSpanEnd(i int) time.Time {
+	return report.suiteStart(i).Add(report.itemDuration(report.xmlSuites[i].Time))
+}
+
+// suiteEnd provides suite span end, with WithItemStartTimeClamp the end is stretched to the end of the last
+// test case or nested suite outlasting the span, so children started within the span end within the suite
+func (report *XMLReport) suiteEnd(i int) time.Time {
+	end := report.suiteSpanEnd(i)
+	if !report.startClamp {
+		return end
+	}
+	// nested suites directly follow their parent suite
+	for k := i; k < len(report.xmlSuites) && (k == i || report.xmlSuites[k].parent > i); k++ {
+		if spanEnd := report.suiteSpanEnd(k); spanEnd.After(end) {
+			end = spanEnd
+		}
+		for j := range report.xmlSuites[k].Cases {
+			if caseEnd := report.TestCaseEndTime(k, j); caseEnd.After(end) {
+				end = caseEnd
+			}
+		}
+	}
+	return end
+}
+
+// clampTime limits time to [min, max] range
+func clampTime(t, min, max time.Time) time.Time {
+	if t.Before(min) {
+		return min
+	}
+	if t.After(max) {
+		return max
+	}
+	return t
+}
+
+// TestCaseEndTime is test case start time plus test case duration
+func (report *XMLReport) TestCaseEndTime(i, j int) time.Time {
+	return report.TestCaseStartTime(i, j).Add(report.itemDuration(report.xmlSuites[i].Cases[j].Time))
+}
+
+// allCasesSkipped is used to check that suite has cases and every one of them is skipped
+func allCasesSkipped(xSuite xmlSuite) bool {
+	if len(xSuite.Cases) == 0 {
+		return false
+	}
+	for _, xCase := range xSuite.Cases {
+		if xCase.Skipped == nil {
+			return false
+		}
+	}
+	return true
+}
 
+// defaultZeroDuration replaces zero suite and case times, since Report Portal could reject item ending at its start
+const defaultZeroDuration = 100 * time.Millisecond
+
+// itemDuration converts suite or case time to duration, zero time is replaced with defaultZeroDuration.
+// Duration shorter than minimal one set by WithMinDuration is raised to it
+func (report *XMLReport) itemDuration(t xmlSeconds) time.Duration {
+	d := t.duration()
+	if t <= 0 {
+		d = defaultZeroDuration
+	}
+	if report.minDuration > 0 && d < report.minDuration {
+		d = report.minDuration
+	}
+	return d
+}
+
+// parseXMLReport is used for parsing report suites sorted by suite start time
+func (report *XMLReport) parseXMLReport(ctx context.Context, reportDir string, match func(name string) bool, decode reportFileDecoder) error {
+	reportFiles, err := report.parseXMLReportFiles(ctx, reportDir, match, decode)
+	if err != nil {
+		return err
+	}
+
+	xSuites := make([]xmlSuite, 0)
+	for _, reportFile := range reportFiles {
+		xSuites = append(xSuites, reportFile.suites...)
+	}
+	report.setSuites(xSuites)
+	return nil
+}
+
+// reportFileDecoder decodes report file content into suites
+type reportFileDecoder func(b []byte) (*xmlReportFile, error)
+
+// parseXMLReportFiles is used for parsing all matched report files from report dir in walk order
+func (report *XMLReport) parseXMLReportFiles(ctx context.Context, reportDir string, match func(name string) bool, decode reportFileDecoder) ([]xmlReportFile, error) {
+	files, infos, err := reportFilePaths(ctx, reportDir, match)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(files)
+	reportFiles := make([]xmlReportFile, 0, n)
+
+	for i := 0; i < n; i++ {
+		// discard partial results on cancellation
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		reportFile := report.readReportFile(files[i], infos[i], decode)
+		if reportFile == nil {
+			continue
+		}
+		reportFiles = append(reportFiles, *reportFile)
+	}
+
+	return reportFiles, nil
+}
+
+// reportFilePaths lists files of report dir accepted by match
+func reportFilePaths(ctx context.Context, reportDir string, match func(name string) bool) ([]string, []os.FileInfo, error) {
 	if len(reportDir) == 0 {
-		return nil, errors.New("report dir could not be empty")
+		return nil, nil, errors.New("report dir could not be empty")
 	}
 
 	files := []string{}
-	filepath.Walk(reportDir, func(path string, f os.FileInfo, err error) error {
-		if filepath.Ext(f.Name()) != ".xml" || f.IsDir() {
+	infos := []os.FileInfo{}
+	err := filepath.Walk(reportDir, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if f.IsDir() || !match(f.Name()) {
 			log.Debugf("not report file '%s'", f.Name())
 		} else {
 			files = append(files, path)
+			infos = append(infos, f)
 		}
 		return nil
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, infos, nil
+}
 
-	n := len(files)
-	xSuites := make([]xmlSuite, 0)
+// readReportFile reads and decodes report file applying report options, nil for file which could not be decoded
+func (report *XMLReport) readReportFile(path string, info os.FileInfo, decode reportFileDecoder) *xmlReportFile {
+	reportFile, err := report.decodeFile(path, decode)
+	if err != nil && report.partialWriteDelay > 0 && time.Since(info.ModTime()) < report.partialWriteWindow {
+		// file could be still being written, so it is read once again
+		log.Warningf("could not decode recently modified '%s', retry in %s: %v", path, report.partialWriteDelay, err)
+		time.Sleep(report.partialWriteDelay)
+		reportFile, err = report.decodeFile(path, decode)
+	}
+	if err != nil {
+		log.Error(err)
+		return nil
+	}
+	reportFile.path = path
+	walkSuites(reportFile.suites, func(xSuite *xmlSuite) {
+		xSuite.source = filepath.Base(path)
+	})
+	if report.modTimeFallback {
+		setModTimeStamps(reportFile, info)
+	}
+	report.finishSuites(reportFile)
+	return reportFile
+}
 
-	for i := 0; i < n; i++ {
-		f := files[i]
-		xmlFile, err := os.Open(f)
-		defer xmlFile.Close()
+// finishSuites applies report options to suites of decoded report file, the same way for report files read from
+// directory and for documents decoded by DecodeSuite
+func (report *XMLReport) finishSuites(reportFile *xmlReportFile) {
+	report.addLaunchAttributes(reportFile)
+	if report.maxDuration > 0 {
+		report.clampDurations(reportFile)
+	}
+	if report.startFromFirstCase {
+		setFirstCaseTimeStamps(reportFile)
+	}
+	if report.systemErrFailures {
+		setSystemErrFailures(reportFile)
+	}
+}
+
+// addLaunchAttributes keeps report file root <testsuites> properties as launch attributes,
+// property of later file overrides the same property of previous files
+func (report *XMLReport) addLaunchAttributes(reportFile *xmlReportFile) {
+	for _, xProperty := range reportFile.properties {
+		if report.launchAttributes == nil {
+			report.launchAttributes = make(map[string]string)
+		}
+		report.launchAttributes[xProperty.Name] = xProperty.Value
+	}
+}
+
+// setRawXML keeps report file content with file top level suites when enabled by WithRawXML. Every suite of
+// <testsuites> file keeps its own <testsuite> element, otherwise whole file is kept by the first suite only,
+// so the same content is not attached several times. Kept content is copied, since b is pooled read buffer
+func (report *XMLReport) setRawXML(reportFile *xmlReportFile, b []byte) {
+	if !report.rawXML || len(reportFile.suites) == 0 {
+		return
+	}
+	if reportFile.aggregate {
+		if parts := splitSuitesXML(b); len(parts) == len(reportFile.suites) {
+			for k := range reportFile.suites {
+				reportFile.suites[k].raw = parts[k]
+			}
+			return
+		}
+	}
+	reportFile.suites[0].raw = append([]byte(nil), b...)
+}
+
+// splitSuitesXML provides copies of <testsuite> elements of <testsuites> document in document order,
+// nil for document which could not be tokenized
+func splitSuitesXML(b []byte) [][]byte {
+	var parts [][]byte
+	d := xml.NewDecoder(bytes.NewReader(b))
+	depth := 0
+	var start int64
+	for {
+		offset := d.InputOffset()
+		t, err := d.Token()
+		if err == io.EOF {
+			return parts
+		}
 		if err != nil {
-			log.Error(err)
-			continue
+			return nil
+		}
+		switch e := t.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 && e.Name.Local == "testsuite" {
+				start = offset
+			}
+		case xml.EndElement:
+			if depth == 2 && e.Name.Local == "testsuite" {
+				parts = append(parts, append([]byte(nil), b[start:d.InputOffset()]...))
+			}
+			depth--
+		}
+	}
+}
+
+// setModTimeStamps sets file modification time as start time of file suites without timestamp
+func setModTimeStamps(reportFile *xmlReportFile, info os.FileInfo) {
+	walkSuites(reportFile.suites, func(xSuite *xmlSuite) {
+		if len(xSuite.TimeStamp) == 0 {
+			xSuite.TimeStamp = info.ModTime().UTC().Format(TimestampLayout)
+		}
+	})
+}
+
+// setFirstCaseTimeStamps sets the earliest case timestamp as start time of file suites with timestamped cases
+func setFirstCaseTimeStamps(reportFile *xmlReportFile) {
+	walkSuites(reportFile.suites, func(xSuite *xmlSuite) {
+		var first time.Time
+		for _, xCase := range xSuite.Cases {
+			if len(xCase.TimeStamp) == 0 {
+				continue
+			}
+			start := parseTimeStamp(xCase.TimeStamp)
+			if !start.IsZero() && (first.IsZero() || start.Before(first)) {
+				first = start
+			}
+		}
+		if !first.IsZero() {
+			xSuite.TimeStamp = first.Format(TimestampLayout)
+		}
+	})
+}
+
+// passingStatuses are test case status attribute values which are not treated as failure by setSystemErrFailures
+var passingStatuses = map[string]bool{
+	"":         true,
+	"pass":     true,
+	"passed":   true,
+	"success":  true,
+	"ok":       true,
+	"skip":     true,
+	"skipped":  true,
+	"disabled": true,
+}
+
+// setSystemErrFailures adds error to test cases without failure, error or skipped element having non-passing
+// status attribute along with system-err output, error is counted in suite errors
+func setSystemErrFailures(reportFile *xmlReportFile) {
+	walkSuites(reportFile.suites, func(xSuite *xmlSuite) {
+		for j := range xSuite.Cases {
+			xCase := &xSuite.Cases[j]
+			if xCase.Failure != nil || xCase.Error != nil || xCase.Skipped != nil {
+				continue
+			}
+			if passingStatuses[strings.ToLower(xCase.Status)] || len(strings.TrimSpace(xCase.SystemErr)) == 0 {
+				continue
+			}
+			// system-err output itself is logged as test case log
+			xCase.Error = &xmlFailure{
+				Type:    "system-err",
+				Message: fmt.Sprintf("test case status is '%s' with system-err output", xCase.Status),
+			}
+			xSuite.Errors++
 		}
+	})
+}
 
-		b, err := ioutil.ReadAll(xmlFile)
+// clampDurations limits suite and case times of report file to [0, max duration] range
+func (report *XMLReport) clampDurations(reportFile *xmlReportFile) {
+	walkSuites(reportFile.suites, func(xSuite *xmlSuite) {
+		xSuite.Time = report.clampSeconds(xSuite.Time, "suite '"+xSuite.Name+"'", reportFile.path)
+		for j := range xSuite.Cases {
+			xCase := &xSuite.Cases[j]
+			xCase.Time = report.clampSeconds(xCase.Time, "test case '"+xCase.Name+"'", reportFile.path)
+		}
+	})
+}
+
+// clampSeconds limits time to [0, max duration] range, warning about clamped value
+func (report *XMLReport) clampSeconds(t xmlSeconds, item, path string) xmlSeconds {
+	max := xmlSeconds(report.maxDuration.Seconds())
+	if t < 0 {
+		log.Warningf("%s time %v from '%s' is clamped to 0", item, float64(t), path)
+		return 0
+	}
+	if t > max {
+		log.Warningf("%s time %v from '%s' is clamped to %v", item, float64(t), path, float64(max))
+		return max
+	}
+	return t
+}
+
+// isXMLFile is default report file match by .xml extension
+func isXMLFile(name string) bool {
+	return filepath.Ext(name) == ".xml"
+}
+
+// decodeXMLReportFile is used for decoding single <testsuite> or <testsuites> aggregate document
+func decodeXMLReportFile(b []byte) (*xmlReportFile, error) {
+	root, err := rootElementName(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if root == "testsuites" {
+		var xAggregate xmlAggregate
+		err = xml.Unmarshal(b, &xAggregate)
 		if err != nil {
-			log.Error(err)
-			continue
+			return nil, err
 		}
+		walkSuites(xAggregate.Suites, setCaseOrdinals)
+		walkSuites(xAggregate.Suites, setDisabledSkipped)
+		return &xmlReportFile{
+			aggregate:  true,
+			properties: xAggregate.Properties.Properties,
+			suites:     xAggregate.Suites,
+		}, nil
+	}
+
+	var xSuite xmlSuite
+	err = xml.Unmarshal(b, &xSuite)
+	if err != nil {
+		return nil, err
+	}
+	xSuites := []xmlSuite{xSuite}
+	walkSuites(xSuites, setCaseOrdinals)
+	walkSuites(xSuites, setDisabledSkipped)
+	return &xmlReportFile{
+		suites: xSuites,
+	}, nil
+}
 
-		var xSuite xmlSuite
-		err = xml.Unmarshal(b, &xSuite)
+// setCaseOrdinals stores document order of suite test cases
+func setCaseOrdinals(xSuite *xmlSuite) {
+	for j := range xSuite.Cases {
+		xSuite.Cases[j].ordinal = j
+	}
+}
+
+// setDisabledSkipped marks disabled test cases as skipped
+func setDisabledSkipped(xSuite *xmlSuite) {
+	for j := range xSuite.Cases {
+		xCase := &xSuite.Cases[j]
+		if xCase.Skipped == nil && isDisabled(*xCase) {
+			xCase.Skipped = &xmlSkipped{Message: "disabled"}
+			if xCase.Disabled != nil && len(xCase.Disabled.Message) != 0 {
+				xCase.Skipped.Message = xCase.Disabled.Message
+			}
+		}
+	}
+}
+
+// isDisabled checks test case for status="disabled" (JUnit5 @Disabled test) or <disabled/> element
+func isDisabled(xCase xmlTest) bool {
+	return xCase.Status == "disabled" || xCase.Disabled != nil
+}
+
+// rootElementName provides local name of xml document root element
+func rootElementName(b []byte) (string, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		t, err := d.Token()
 		if err != nil {
-			log.Error(err)
+			return "", err
+		}
+		if e, ok := t.(xml.StartElement); ok {
+			return e.Name.Local, nil
+		}
+	}
+}
+
+// walkSuites calls fn for every suite including nested ones, parents before their children
+func walkSuites(xSuites []xmlSuite, fn func(xSuite *xmlSuite)) {
+	for k := range xSuites {
+		fn(&xSuites[k])
+		walkSuites(xSuites[k].Suites, fn)
+	}
+}
+
+// setSuites sorts top level suites by configured suite order and stores them with nested suites flattened
+func (report *XMLReport) setSuites(xSuites []xmlSuite) {
+	if report.mergeSplitSuites {
+		xSuites = mergeSplitSuites(xSuites)
+	}
+	report.sortSuites(xSuites)
+	report.xmlSuites = flattenSuites(xSuites)
+	report.setCaseOffsets(report.xmlSuites)
+}
+
+// setCaseOffsets precomputes start offsets of test cases, so estimating case start time does not
+// iterate over previous cases of the suite
+func (report *XMLReport) setCaseOffsets(xSuites []xmlSuite) {
+	for i := range xSuites {
+		var offset time.Duration
+		for j := range xSuites[i].Cases {
+			xCase := &xSuites[i].Cases[j]
+			xCase.offset = offset
+			offset += report.itemDuration(xCase.Time)
+		}
+	}
+}
+
+// mergeSplitSuites merges top level suites with the same package and name, e.g. written by parallel runners
+// from several hosts: cases are concatenated, counters summed and suite spans from earliest start to latest end
+func mergeSplitSuites(xSuites []xmlSuite) []xmlSuite {
+	merged := make([]xmlSuite, 0, len(xSuites))
+	index := make(map[[2]string]int)
+	for _, xSuite := range xSuites {
+		key := [2]string{xSuite.PackageName, xSuite.Name}
+		k, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, xSuite)
 			continue
 		}
 
-		xSuites = append(xSuites, xSuite)
+		xMerged := &merged[k]
+		start, end := suiteSpan(*xMerged)
+		partStart, partEnd := suiteSpan(xSuite)
+		// part without timestamp does not change span, since its zero start time is meaningless
+		switch {
+		case partStart.IsZero():
+		case start.IsZero():
+			xMerged.TimeStamp = xSuite.TimeStamp
+			xMerged.Time = xSuite.Time
+		default:
+			if partStart.Before(start) {
+				start = partStart
+				xMerged.TimeStamp = xSuite.TimeStamp
+			}
+			if partEnd.After(end) {
+				end = partEnd
+			}
+			xMerged.Time = xmlSeconds(end.Sub(start).Seconds())
+		}
+		xMerged.Tests += xSuite.Tests
+		xMerged.Failures += xSuite.Failures
+		xMerged.Errors += xSuite.Errors
+		xMerged.Skipped += xSuite.Skipped
+		xMerged.Cases = append(xMerged.Cases, xSuite.Cases...)
+		xMerged.Suites = append(xMerged.Suites, xSuite.Suites...)
+		setCaseOrdinals(xMerged)
 	}
+	return merged
+}
+
+// suiteSpan provides suite start and end time
+func suiteSpan(xSuite xmlSuite) (time.Time, time.Time) {
+	start := parseTimeStamp(xSuite.TimeStamp)
+	return start, start.Add(xSuite.Time.duration())
+}
 
-	// sort by start time
-	sort.Slice(xSuites, func(i, j int) bool {
-		t1 := parseTimeStamp(xSuites[i].TimeStamp)
-		t2 := parseTimeStamp(xSuites[j].TimeStamp)
+// flattenSuites lists suites depth-first, every suite is followed by its nested suites which keep link to parent
+func flattenSuites(xSuites []xmlSuite) []xmlSuite {
+	flat := make([]xmlSuite, 0, len(xSuites))
+	var add func(xSuites []xmlSuite, parent int)
+	add = func(xSuites []xmlSuite, parent int) {
+		for _, xSuite := range xSuites {
+			children := xSuite.Suites
+			xSuite.Suites = nil
+			xSuite.parent = parent
+			flat = append(flat, xSuite)
+			add(children, len(flat))
+		}
+	}
+	add(xSuites, 0)
+	return flat
+}
+
+// sortSuites by configured suite order: start time, id or name, suites are kept in read order with WithNoSort
+func (report *XMLReport) sortSuites(xSuites []xmlSuite) {
+	if report.noSort {
+		return
+	}
+	sort.SliceStable(xSuites, func(i, j int) bool {
+		switch report.suiteOrder {
+		case SuiteOrderByID:
+			return xSuites[i].ID < xSuites[j].ID
+		case SuiteOrderByName:
+			if xSuites[i].PackageName != xSuites[j].PackageName {
+				return xSuites[i].PackageName < xSuites[j].PackageName
+			}
+			return xSuites[i].Name < xSuites[j].Name
+		}
+		t1 := report.timeStamp(xSuites[i].TimeStamp)
+		t2 := report.timeStamp(xSuites[j].TimeStamp)
 		return t1.Before(t2)
 	})
-
-	return xSuites, nil
 }
@@ -0,0 +1,1005 @@
+package rp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeSplitSuitesWithoutTimeStamp(t *testing.T) {
+	report, err := LoadXMLReport("testdata/split", WithMergeSplitSuites(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SuitesCount() != 1 {
+		t.Fatalf("expected 1 merged suite, got %d", report.SuitesCount())
+	}
+
+	start := report.Suite(0).StartTime
+	wantStart := time.Date(2017, 5, 5, 20, 3, 50, 0, time.UTC)
+	if !start.Equal(wantStart) {
+		t.Errorf("expected start %s, got %s", wantStart, start)
+	}
+	if d := report.Stats().Duration; d != 2*time.Second {
+		t.Errorf("expected duration of timestamped part 2s, got %s", d)
+	}
+	if n := report.TesCaseCount(0); n != 2 {
+		t.Errorf("expected 2 merged cases, got %d", n)
+	}
+}
+
+// largeSuite provides single suite document with n cases lasting 1s each
+func largeSuite(n int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<testsuite name="Large" package="p" timestamp="2017-05-05T20:03:50.000Z" time="%d" tests="%d">`, n, n)
+	for j := 0; j < n; j++ {
+		fmt.Fprintf(&b, `<testcase name="case%d" classname="p.Large" time="1"/>`, j)
+	}
+	b.WriteString(`</testsuite>`)
+	return b.String()
+}
+
+func TestCaseStartTimeCumulative(t *testing.T) {
+	report, err := DecodeSuite(strings.NewReader(largeSuite(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	suiteStart := report.Suite(0).StartTime
+	for j := 0; j < report.TesCaseCount(0); j++ {
+		want := suiteStart.Add(time.Duration(j) * time.Second)
+		if start := report.TestCaseStartTime(0, j); !start.Equal(want) {
+			t.Errorf("case %d: expected start %s, got %s", j, want, start)
+		}
+	}
+}
+
+func BenchmarkTestCaseStartTime(b *testing.B) {
+	report, err := DecodeSuite(strings.NewReader(largeSuite(10000)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for j := 0; j < report.TesCaseCount(0); j++ {
+			report.TestCaseStartTime(0, j)
+		}
+	}
+}
+
+func TestCaseStartTimeClampedToSuiteSpan(t *testing.T) {
+	report, err := LoadXMLReport("testdata/clamp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	suiteStart := report.Suite(0).StartTime
+	suiteEnd := report.SuiteResult(0).EndTime
+	spanEnd := suiteStart.Add(time.Millisecond)
+	if start := report.TestCaseStartTime(0, 0); !start.Equal(suiteStart) {
+		t.Errorf("expected case started before suite to start at %s, got %s", suiteStart, start)
+	}
+	if start := report.TestCaseStartTime(0, 3); !start.Equal(spanEnd) {
+		t.Errorf("expected case started after suite to start at %s, got %s", spanEnd, start)
+	}
+	for j := 0; j < report.TesCaseCount(0); j++ {
+		if start := report.TestCaseStartTime(0, j); start.Before(suiteStart) || start.After(spanEnd) {
+			t.Errorf("expected case %d start %s within suite span [%s, %s]", j, start, suiteStart, spanEnd)
+		}
+		// suite outlasted by its cases ends with the last case
+		if end := report.TestCaseEndTime(0, j); end.After(suiteEnd) {
+			t.Errorf("expected case %d end %s not after suite end %s", j, end, suiteEnd)
+		}
+	}
+
+	report, err = LoadXMLReport("testdata/clamp", WithItemStartTimeClamp(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2017, 5, 5, 20, 3, 40, 0, time.UTC)
+	if start := report.TestCaseStartTime(0, 0); !start.Equal(want) {
+		t.Errorf("expected unclamped start %s, got %s", want, start)
+	}
+	want = time.Date(2017, 5, 5, 20, 4, 0, 0, time.UTC)
+	if start := report.TestCaseStartTime(0, 3); !start.Equal(want) {
+		t.Errorf("expected unclamped start %s, got %s", want, start)
+	}
+	if end := report.SuiteResult(0).EndTime; !end.Equal(spanEnd) {
+		t.Errorf("expected unclamped suite end %s, got %s", spanEnd, end)
+	}
+}
+
+func TestRegroupByClassNameCounters(t *testing.T) {
+	report, err := LoadXMLReport("testdata/regroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	regrouped := report.RegroupByClassName()
+	if regrouped.SuitesCount() != 3 {
+		t.Fatalf("expected 3 class name suites, got %d", regrouped.SuitesCount())
+	}
+
+	want := map[string]struct{ tests, failures, errors, skipped int }{
+		"Alpha": {2, 0, 0, 1},
+		"Beta":  {2, 1, 0, 0},
+		"Gamma": {2, 0, 2, 0},
+	}
+	for i := 0; i < regrouped.SuitesCount(); i++ {
+		xSuite := regrouped.xmlSuites[i]
+		counters, ok := want[xSuite.Name]
+		if !ok {
+			t.Errorf("unexpected suite %s", xSuite.Name)
+			continue
+		}
+		if xSuite.Tests != counters.tests || xSuite.Failures != counters.failures ||
+			xSuite.Errors != counters.errors || xSuite.Skipped != counters.skipped {
+			t.Errorf("suite %s: expected tests/failures/errors/skipped %v, got %d/%d/%d/%d", xSuite.Name, counters,
+				xSuite.Tests, xSuite.Failures, xSuite.Errors, xSuite.Skipped)
+		}
+	}
+
+	summary, regroupedSummary := report.Summary(), regrouped.Summary()
+	if summary.Errors != regroupedSummary.Errors {
+		t.Errorf("expected %d errors of regrouped report, got %d", summary.Errors, regroupedSummary.Errors)
+	}
+}
+
+func FuzzLoadXMLReportFromReader(f *testing.F) {
+	seeds, err := filepath.Glob("testdata/bad/*.xml")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, seed := range seeds {
+		b, err := os.ReadFile(seed)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Add([]byte(largeSuite(3)))
+	f.Add([]byte(`<testsuites><testsuite><testsuite><testcase/></testsuite></testsuite></testsuites>`))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		report, err := LoadXMLReportFromReader(bytes.NewReader(b), WithRawXML(true), WithMergeSplitSuites(true))
+		if err != nil {
+			return
+		}
+		// every accessor used by Publish should cope with decoded report
+		report.LaunchStartTime()
+		report.LaunchEndTime()
+		report.Summary()
+		for i := 0; i < report.SuitesCount(); i++ {
+			report.Suite(i)
+			report.SuiteResult(i)
+			report.SuitePropertiesLog(i)
+			report.SuiteOverheadLog(i)
+			report.SuiteRawXML(i)
+			report.SuiteChildren(i)
+			for j := 0; j < report.TesCaseCount(i); j++ {
+				report.TestCase(i, j)
+				report.TestCaseResult(i, j)
+				report.TestCaseLogs(i, j)
+				report.TestCaseFailure(i, j)
+				report.TestCaseFullName(i, j)
+			}
+		}
+	})
+}
+
+func TestFailureTypeLevels(t *testing.T) {
+	report, err := LoadXMLReport("testdata/levels", WithFailureTypeLevels(map[string]LogLevel{"WARN": LogLevelWarn}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		message string
+		level   LogLevel
+	}{
+		{"slow response", LogLevelWarn},
+		{"deprecated api", LogLevelWarn},
+		{"boom", LogLevelError},
+	}
+	for j, w := range want {
+		if level := report.TestCaseFailure(0, j).Level; level != w.level {
+			t.Errorf("case %d: expected failure level %s, got %s", j, w.level, level)
+		}
+		var found bool
+		for _, logMessage := range report.TestCaseLogs(0, j) {
+			if logMessage.Message == w.message {
+				found = true
+				if logMessage.Level != w.level {
+					t.Errorf("case %d: expected log level %s, got %s", j, w.level, logMessage.Level)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("case %d: no log with message %s", j, w.message)
+		}
+	}
+}
+
+// caseDurations provides durations of test cases of suite loaded from given single suite document
+func caseDurations(t *testing.T, path string) (time.Duration, []time.Duration) {
+	report, err := DecodeSuite(strings.NewReader(readTestFile(t, path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var durations []time.Duration
+	for j := 0; j < report.TesCaseCount(0); j++ {
+		durations = append(durations, report.TestCaseEndTime(0, j).Sub(report.TestCaseStartTime(0, j)))
+	}
+	return report.Stats().Duration, durations
+}
+
+func TestTimeCommaDecimalSeparator(t *testing.T) {
+	suiteDuration, durations := caseDurations(t, "testdata/times/comma.xml")
+	if suiteDuration != 2500*time.Millisecond {
+		t.Errorf("expected suite duration 2.5s, got %s", suiteDuration)
+	}
+	if want := []time.Duration{500 * time.Millisecond, 2 * time.Second}; !reflect.DeepEqual(durations, want) {
+		t.Errorf("expected case durations %v, got %v", want, durations)
+	}
+}
+
+func TestTimeClockFormat(t *testing.T) {
+	suiteDuration, durations := caseDurations(t, "testdata/times/clock.xml")
+	if want := time.Hour + 2*time.Minute + 3500*time.Millisecond; suiteDuration != want {
+		t.Errorf("expected suite duration %s, got %s", want, suiteDuration)
+	}
+	if want := []time.Duration{1250 * time.Millisecond, 150 * time.Second, time.Hour}; !reflect.DeepEqual(durations, want) {
+		t.Errorf("expected case durations %v, got %v", want, durations)
+	}
+
+	for _, value := range []string{"1:2:3:4", "-1:00", "aa:10"} {
+		var s xmlSeconds
+		if err := s.UnmarshalXMLAttr(xml.Attr{Value: value}); err == nil {
+			t.Errorf("expected error for time %q, got %v", value, s)
+		}
+	}
+}
+
+func TestDisabledCasesSkipped(t *testing.T) {
+	report, err := LoadXMLReport("testdata/disabled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		disabled bool
+		status   ExecutionStatus
+		message  string
+	}{
+		{true, ExecutionStatusSkipped, "disabled"},
+		{true, ExecutionStatusSkipped, "flaky on CI"},
+		{false, ExecutionStatusPassed, ""},
+	}
+	for j, w := range want {
+		if disabled := report.TestCaseIsDisabled(0, j); disabled != w.disabled {
+			t.Errorf("case %d: expected disabled %t, got %t", j, w.disabled, disabled)
+		}
+		if status := report.TestCaseResult(0, j).Status; status != w.status {
+			t.Errorf("case %d: expected status %s, got %s", j, w.status, status)
+		}
+		if !w.disabled {
+			continue
+		}
+		if skipped := report.TesCaseSkippedMessage(0, j); skipped.Message != w.message {
+			t.Errorf("case %d: expected skipped message %q, got %q", j, w.message, skipped.Message)
+		}
+	}
+}
+
+func TestSuiteResultOnlySkipped(t *testing.T) {
+	report, err := LoadXMLReport("testdata/skipped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]ExecutionStatus{
+		"s.AllSkipped": ExecutionStatusSkipped,
+		"s.Empty":      ExecutionStatusSkipped,
+		"s.Mixed":      ExecutionStatusPassed,
+	}
+	if n := report.SuitesCount(); n != len(want) {
+		t.Fatalf("expected %d suites, got %d", len(want), n)
+	}
+	for i := 0; i < report.SuitesCount(); i++ {
+		name := report.Suite(i).Name
+		if status := report.SuiteResult(i).Status; status != want[name] {
+			t.Errorf("suite %s: expected status %s, got %s", name, want[name], status)
+		}
+	}
+}
+
+func TestLoadXMLReportContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err := LoadXMLReportContext(ctx, "testdata/transform")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if report != nil {
+		t.Error("expected no partial report on cancellation")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := LoadXMLReportContext(ctx, "testdata/transform"); err != nil {
+		t.Errorf("expected report loaded with live context, got %v", err)
+	}
+}
+
+func TestMustLoadXMLReport(t *testing.T) {
+	if report := MustLoadXMLReport("testdata/transform"); report.SuitesCount() != 3 {
+		t.Errorf("expected 3 suites, got %d", report.SuitesCount())
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "MustLoadXMLReport") {
+			t.Errorf("expected MustLoadXMLReport panic, got %v", r)
+		}
+	}()
+	MustLoadXMLReport("testdata/missing")
+}
+
+// suiteNames provides space separated names of report suites in report order
+func suiteNames(report *XMLReport) string {
+	names := make([]string, 0, report.SuitesCount())
+	for i := 0; i < report.SuitesCount(); i++ {
+		names = append(names, report.Suite(i).Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestLoadXMLReportDeaggregate(t *testing.T) {
+	report, err := LoadXMLReport("testdata/deaggregate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "m.Core m.Core m.Web m.Cli"; got != want {
+		t.Errorf("expected suites of aggregate and module files %q, got %q", want, got)
+	}
+
+	report, err = LoadXMLReportDeaggregate("testdata/deaggregate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "m.Core m.Web m.Cli"; got != want {
+		t.Errorf("expected aggregated suites once %q, got %q", want, got)
+	}
+}
+
+func TestSuitePropertiesLog(t *testing.T) {
+	report, err := LoadXMLReport("testdata/properties")
+	if err != nil {
+		t.Fatal(err)
+	}
+	propertiesLog := report.SuitePropertiesLog(0)
+	if propertiesLog == nil {
+		t.Fatal("expected properties log")
+	}
+	if want := "java.version=1.8.0_131\nos.name=Linux"; propertiesLog.Message != want {
+		t.Errorf("expected message %q, got %q", want, propertiesLog.Message)
+	}
+	if propertiesLog.Level != LogLevelInfo || !propertiesLog.Time.Equal(report.Suite(0).StartTime) {
+		t.Errorf("expected info log at suite start, got %s at %s", propertiesLog.Level, propertiesLog.Time)
+	}
+
+	report, err = LoadXMLReport("testdata/transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if propertiesLog := report.SuitePropertiesLog(0); propertiesLog != nil {
+		t.Errorf("expected no log for suite without properties, got %q", propertiesLog.Message)
+	}
+}
+
+func TestCaseStartBeforeEnd(t *testing.T) {
+	report, err := LoadXMLReport("testdata/durations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := 0; j < report.TesCaseCount(0); j++ {
+		start, end := report.TestCaseStartTime(0, j), report.TestCaseEndTime(0, j)
+		if !start.Before(end) {
+			t.Errorf("case %d: expected start %s before end %s", j, start, end)
+		}
+		if tCase := report.TestCase(0, j); !tCase.StartTime.Equal(start) {
+			t.Errorf("case %d: expected TestCase start %s, got %s", j, start, tCase.StartTime)
+		}
+		if result := report.TestCaseResult(0, j); !result.EndTime.Equal(end) {
+			t.Errorf("case %d: expected TestCaseResult end %s, got %s", j, end, result.EndTime)
+		}
+	}
+	// case of 4.5s lasts exactly its time
+	if d := report.TestCaseEndTime(0, 4).Sub(report.TestCaseStartTime(0, 4)); d != 4500*time.Millisecond {
+		t.Errorf("expected case duration 4.5s, got %s", d)
+	}
+}
+
+func TestNormalizePathClassName(t *testing.T) {
+	report, err := LoadXMLReport("testdata/windows", WithClassNameNormalizer(NormalizePathClassName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := 0; j < report.TesCaseCount(0); j++ {
+		if className := report.TestCaseClassName(0, j); className != "com.example.Paths" {
+			t.Errorf("case %d: expected class name com.example.Paths, got %q", j, className)
+		}
+	}
+
+	report, err = LoadXMLReport("testdata/windows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if className := report.TestCaseClassName(0, 0); className != `com\example\Paths` {
+		t.Errorf("expected class name kept by default, got %q", className)
+	}
+}
+
+func TestCaseOrdinalDocumentOrder(t *testing.T) {
+	report, err := LoadXMLReport("testdata/order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cases keep document order regardless of their timestamps
+	for j, name := range []string{"a", "b", "c"} {
+		if tCase := report.TestCase(0, j); tCase.Name != name || report.TestCaseOrdinal(0, j) != j {
+			t.Errorf("case %d: expected %s with ordinal %d, got %s with ordinal %d", j, name, j, tCase.Name, report.TestCaseOrdinal(0, j))
+		}
+	}
+
+	// ordinal is position in the source document even if preceding cases are dropped
+	transformed := report.Transform(func(suiteName string, c *CaseView) bool {
+		return c.Name != "a"
+	})
+	if ordinal := transformed.TestCaseOrdinal(0, 0); ordinal != 1 {
+		t.Errorf("expected ordinal 1 of kept case b, got %d", ordinal)
+	}
+}
+
+func TestLoadXMLReportMatch(t *testing.T) {
+	report, err := LoadXMLReport("testdata/match")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "lint.Checkstyle p.Props"; got != want {
+		t.Errorf("expected every xml file loaded %q, got %q", want, got)
+	}
+
+	var names []string
+	report, err = LoadXMLReportMatch("testdata/match", func(name string) bool {
+		names = append(names, name)
+		return name != "checkstyle.xml"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "p.Props"; got != want {
+		t.Errorf("expected checkstyle.xml excluded %q, got %q", want, got)
+	}
+	if want := []string{"TEST-p.Props.xml", "checkstyle.xml"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected match called with file names %v, got %v", want, names)
+	}
+}
+
+func TestCaseDescription(t *testing.T) {
+	report, err := LoadXMLReport("testdata/description")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, want := range []string{"User logs in with valid credentials", ""} {
+		if description := report.TestCase(0, j).Description; description != want {
+			t.Errorf("case %d: expected description %q, got %q", j, want, description)
+		}
+	}
+}
+
+func TestModTimeFallback(t *testing.T) {
+	dir := t.TempDir()
+	modTimes := map[string]time.Time{
+		"Early.xml":   time.Date(2017, 5, 5, 20, 3, 50, 0, time.UTC),
+		"Stamped.xml": time.Date(2017, 5, 5, 20, 3, 40, 0, time.UTC),
+		"Late.xml":    time.Date(2017, 5, 5, 20, 3, 55, 0, time.UTC),
+	}
+	for name, modTime := range modTimes {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(readTestFile(t, filepath.Join("testdata/modtime", name))), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := LoadXMLReport(dir, WithModTimeFallback(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// timestamp attribute takes precedence over modification time
+	if got, want := suiteNames(report), "m.Early m.Stamped m.Late"; got != want {
+		t.Errorf("expected suites ordered by modification time %q, got %q", want, got)
+	}
+	if start := report.Suite(0).StartTime; !start.Equal(modTimes["Early.xml"]) {
+		t.Errorf("expected start time %s of timestamp-less suite, got %s", modTimes["Early.xml"], start)
+	}
+
+	report, err = LoadXMLReport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "m.Early m.Late m.Stamped"; got != want {
+		t.Errorf("expected timestamp-less suites first by default %q, got %q", want, got)
+	}
+}
+
+func TestDurationClamp(t *testing.T) {
+	report, err := LoadXMLReport("testdata/garbage", WithDurationClamp(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := report.Stats().Duration; d != time.Hour {
+		t.Errorf("expected suite time clamped to 1h, got %s", d)
+	}
+	// negative time is clamped to zero, which is reported as default zero duration
+	want := []time.Duration{defaultZeroDuration, time.Hour, 2 * time.Second}
+	for j, w := range want {
+		if d := report.TestCaseEndTime(0, j).Sub(report.TestCaseStartTime(0, j)); d != w {
+			t.Errorf("case %d: expected duration %s, got %s", j, w, d)
+		}
+	}
+}
+
+func TestDecodeSuite(t *testing.T) {
+	report, err := DecodeSuite(strings.NewReader(`<testsuite name="Inline" package="i" timestamp="2017-05-05T20:03:50.000Z" time="2" tests="2" failures="1">
+  <testcase name="ok" classname="i.Inline" time="1"/>
+  <testcase name="ko" classname="i.Inline" time="1"><failure message="expected"/></testcase>
+</testsuite>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suiteNames(report); got != "i.Inline" {
+		t.Errorf("expected suite i.Inline, got %q", got)
+	}
+	if stats := report.Stats(); stats.Tests != 2 || stats.Failures != 1 {
+		t.Errorf("expected 2 tests with 1 failure, got %+v", stats)
+	}
+	if !report.HasTestCaseFailure(0, 1) {
+		t.Error("expected failure of the second case")
+	}
+
+	if _, err := DecodeSuite(strings.NewReader(`<testsuite name="Truncated">`)); err == nil {
+		t.Error("expected error for truncated document")
+	}
+}
+
+func TestDecodeSuiteAppliesReportOptionsAsLoad(t *testing.T) {
+	opts := []ReportOption{WithDurationClamp(time.Minute), WithSystemErrFailures(true), WithStartTimeFromFirstCase(true)}
+	loaded, err := LoadXMLReport("testdata/durations", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeSuite(strings.NewReader(readTestFile(t, "testdata/durations/cases.xml")), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.SuiteResult(0), loaded.SuiteResult(0)) {
+		t.Errorf("expected decoded suite result %+v, got %+v", loaded.SuiteResult(0), decoded.SuiteResult(0))
+	}
+	for j := 0; j < loaded.TesCaseCount(0); j++ {
+		if want, got := loaded.TestCaseResult(0, j), decoded.TestCaseResult(0, j); !reflect.DeepEqual(got, want) {
+			t.Errorf("case %d: expected decoded result %+v, got %+v", j, want, got)
+		}
+	}
+}
+
+func TestDefaultDescription(t *testing.T) {
+	report, err := LoadXMLReport("testdata/transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "5 tests, 1 failed, 1 errored, 1 skipped in 6s"
+	if description := report.DefaultDescription(); description != want {
+		t.Errorf("expected description %q, got %q", want, description)
+	}
+
+	// launch without description defaults to report description
+	rp := newFakeRP(t)
+	if _, err := rp.client().Publish(report, &Launch{Name: "description"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rp.launches) != 1 || rp.launches[0].Description != want {
+		t.Errorf("expected launch description %q, got %+v", want, rp.launches)
+	}
+}
+
+func TestLoadXMLReportSince(t *testing.T) {
+	since := time.Date(2017, 5, 5, 0, 0, 0, 0, time.UTC)
+	report, err := LoadXMLReportSince("testdata/since", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stale and timestamp-less suites are skipped
+	if got, want := suiteNames(report), "s.New"; got != want {
+		t.Errorf("expected suites %q, got %q", want, got)
+	}
+}
+
+func TestSuiteFailureAndErrorCounts(t *testing.T) {
+	report, err := LoadXMLReport("testdata/regroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := report.SuiteFailureCount(0); n != 1 {
+		t.Errorf("expected 1 failure, got %d", n)
+	}
+	if n := report.SuiteErrorCount(0); n != 2 {
+		t.Errorf("expected 2 errors, got %d", n)
+	}
+	if status := report.SuiteResult(0).Status; status != ExecutionStatusFailed {
+		t.Errorf("expected failed suite, got %s", status)
+	}
+	if description := report.DefaultDescription(); !strings.HasPrefix(description, "6 tests, 1 failed, 2 errored, 1 skipped") {
+		t.Errorf("expected failures and errors apart in description, got %q", description)
+	}
+}
+
+func TestCaseLogsOrder(t *testing.T) {
+	report, err := LoadXMLReport("testdata/logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, end := report.TestCaseStartTime(0, 0), report.TestCaseEndTime(0, 0)
+	// blank system-err is omitted
+	want := []LogMessage{
+		{Time: start, Level: LogLevelInfo, Message: "connecting to db", ItemPath: "l.Output.failing"},
+		{Time: end, Level: LogLevelError, Message: "expected 1 but was 2", ItemPath: "l.Output.failing"},
+		{Time: end, Level: LogLevelInfo, Message: "at l.Output.failing(Output.java:10)", ItemPath: "l.Output.failing"},
+	}
+	logs := report.TestCaseLogs(0, 0)
+	if len(logs) != len(want) {
+		t.Fatalf("expected %d logs, got %d", len(want), len(logs))
+	}
+	for k, logMessage := range logs {
+		if !reflect.DeepEqual(*logMessage, want[k]) {
+			t.Errorf("log %d: expected %+v, got %+v", k, want[k], *logMessage)
+		}
+	}
+	if logs := report.TestCaseLogs(0, 1); len(logs) != 0 {
+		t.Errorf("expected no logs of passing case without output, got %d", len(logs))
+	}
+}
+
+func TestSuiteOrder(t *testing.T) {
+	tests := []struct {
+		order SuiteOrder
+		want  string
+	}{
+		{SuiteOrderByTimestamp, "o.A o.C o.B"},
+		{SuiteOrderByID, "o.C o.A o.B"},
+		{SuiteOrderByName, "o.A o.B o.C"},
+	}
+	for _, test := range tests {
+		report, err := LoadXMLReport("testdata/suiteorder", WithSuiteOrder(test.order))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := suiteNames(report); got != test.want {
+			t.Errorf("order %d: expected suites %q, got %q", test.order, test.want, got)
+		}
+	}
+}
+
+func TestEmptySuiteName(t *testing.T) {
+	report, err := LoadXMLReport("testdata/emptyname")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// suite without both package and name is named by report file
+	if got, want := suiteNames(report), "e Bare Anonymous"; got != want {
+		t.Errorf("expected suites %q, got %q", want, got)
+	}
+
+	report, err = DecodeSuite(strings.NewReader(readTestFile(t, "testdata/emptyname/Anonymous.xml")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "SUITE 7"; got != want {
+		t.Errorf("expected suite without file named by id %q, got %q", want, got)
+	}
+}
+
+func TestDefaultPackage(t *testing.T) {
+	report, err := LoadXMLReport("testdata/emptyname", WithDefaultPackage("default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// suites with package or without name keep their names
+	if got, want := suiteNames(report), "e default.Bare Anonymous"; got != want {
+		t.Errorf("expected default package of package-less suite only %q, got %q", want, got)
+	}
+}
+
+func TestSuiteNamePrefixAndSuffix(t *testing.T) {
+	report, err := LoadXMLReport("testdata/emptyname",
+		WithSuiteNamePrefix("[chrome] "), WithSuiteNameSuffix(" (linux)"), WithDefaultPackage("default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"[chrome] e (linux)", "[chrome] default.Bare (linux)", "[chrome] Anonymous (linux)"}
+	if count := report.SuitesCount(); count != len(want) {
+		t.Fatalf("expected %d suites, got %d", len(want), count)
+	}
+	for i, name := range want {
+		if got := report.Suite(i).Name; got != name {
+			t.Errorf("suite %d: expected name %q, got %q", i, name, got)
+		}
+	}
+}
+
+func TestCaseFailureType(t *testing.T) {
+	report, err := LoadXMLReport("testdata/failuretype")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, want := range []string{"org.opentest4j.AssertionFailedError", "", "java.lang.NullPointerException", ""} {
+		if failureType := report.TestCaseFailureType(0, j); failureType != want {
+			t.Errorf("case %d: expected failure type %q, got %q", j, want, failureType)
+		}
+	}
+}
+
+func TestNoSortKeepsReadOrder(t *testing.T) {
+	report, err := LoadXMLReport("testdata/suiteorder", WithNoSort(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "o.B o.C o.A"; got != want {
+		t.Errorf("expected suites in document order %q, got %q", want, got)
+	}
+}
+
+func TestSuiteAndTestCaseReturnCopies(t *testing.T) {
+	report, err := LoadXMLReport("testdata/groups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite := report.Suite(0)
+	suite.Name = "changed"
+	if name := report.Suite(0).Name; name != "g.Tagged" {
+		t.Errorf("expected suite name kept, got %q", name)
+	}
+
+	tCase := report.TestCase(0, 0)
+	tCase.Name = "changed"
+	tCase.Tags[0] = "changed"
+	tCase.Tags = append(tCase.Tags, "added")
+	want := &TestItem{
+		Type:      TestItemTypeStep,
+		Name:      "case",
+		StartTime: report.TestCaseStartTime(0, 0),
+		Tags:      []string{"group:smoke", "group:regression"},
+	}
+	if again := report.TestCase(0, 0); !reflect.DeepEqual(again, want) {
+		t.Errorf("expected test case %+v unaffected by changes, got %+v", want, again)
+	}
+}
+
+func TestStartTimeFromFirstCase(t *testing.T) {
+	at := func(minute, second int) time.Time {
+		return time.Date(2017, 5, 5, 20, minute, second, 0, time.UTC)
+	}
+	for _, firstCase := range []bool{false, true} {
+		report, err := LoadXMLReport("testdata/firstcase", WithStartTimeFromFirstCase(firstCase))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// suite without timestamped cases keeps its timestamp
+		want := map[string]time.Time{"q.Queued": at(0, 0), "q.Untimed": at(1, 0)}
+		if firstCase {
+			want["q.Queued"] = at(3, 50)
+		}
+		if count := report.SuitesCount(); count != len(want) {
+			t.Fatalf("expected %d suites, got %d", len(want), count)
+		}
+		for i := 0; i < report.SuitesCount(); i++ {
+			suite := report.Suite(i)
+			if !suite.StartTime.Equal(want[suite.Name]) {
+				t.Errorf("first case %t: expected %s start %s, got %s", firstCase, suite.Name, want[suite.Name], suite.StartTime)
+			}
+		}
+	}
+}
+
+func TestSystemErrFailures(t *testing.T) {
+	for _, heuristic := range []bool{false, true} {
+		report, err := LoadXMLReport("testdata/systemerr", WithSystemErrFailures(heuristic))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// only non-passing status with system-err output is failure
+		failed := []bool{heuristic, false, false, false}
+		if count := report.TesCaseCount(0); count != len(failed) {
+			t.Fatalf("expected %d test cases, got %d", len(failed), count)
+		}
+		for j, want := range failed {
+			if got := report.TestCaseResult(0, j).IsFailed(); got != want {
+				t.Errorf("heuristic %t: expected %s failure %t, got %t", heuristic, report.TestCase(0, j).Name, want, got)
+			}
+		}
+
+		wantStatus := ExecutionStatusPassed
+		if heuristic {
+			wantStatus = ExecutionStatusFailed
+		}
+		if status := report.SuiteResult(0).Status; status != wantStatus {
+			t.Errorf("heuristic %t: expected suite status %s, got %s", heuristic, wantStatus, status)
+		}
+		if !heuristic {
+			continue
+		}
+		failure := report.TestCaseFailure(0, 0)
+		if failure == nil || failure.Level != LogLevelError || !strings.Contains(failure.Message, "'failed'") {
+			t.Errorf("expected error log of failed status, got %+v", failure)
+		}
+	}
+}
+
+func TestPartialWriteRetry(t *testing.T) {
+	complete := readTestFile(t, "testdata/smoke/cases.xml")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cases.xml")
+	if err := os.WriteFile(path, []byte(complete[:len(complete)/2]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// runner finishes writing file shortly after it is read for the first time
+	written := make(chan error, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		tmp := filepath.Join(t.TempDir(), "cases.xml")
+		err := os.WriteFile(tmp, []byte(complete), 0o644)
+		if err == nil {
+			err = os.Rename(tmp, path)
+		}
+		written <- err
+	}()
+	report, err := LoadXMLReport(dir, WithPartialWriteRetry(time.Minute, 500*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-written; err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "s.Checkout"; got != want {
+		t.Errorf("expected suites %q read on retry, got %q", want, got)
+	}
+
+	// file modified long ago is skipped right away
+	if err := os.WriteFile(path, []byte(complete[:len(complete)/2]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	report, err = LoadXMLReport(dir, WithPartialWriteRetry(time.Minute, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := report.SuitesCount(); count != 0 {
+		t.Errorf("expected truncated file skipped, got %d suites", count)
+	}
+}
+
+func TestMinDuration(t *testing.T) {
+	tests := []struct {
+		opts        []ReportOption
+		suite       time.Duration
+		cases       []time.Duration
+		description string
+	}{
+		{nil, 100 * time.Millisecond, []time.Duration{100 * time.Millisecond, 400 * time.Microsecond, 2 * time.Second}, "default"},
+		{[]ReportOption{WithMinDuration(time.Millisecond)}, 100 * time.Millisecond, []time.Duration{100 * time.Millisecond, time.Millisecond, 2 * time.Second}, "1ms"},
+		{[]ReportOption{WithMinDuration(time.Second)}, time.Second, []time.Duration{time.Second, time.Second, 2 * time.Second}, "1s"},
+	}
+	for _, test := range tests {
+		// suite end is not stretched to its cases without clamping
+		opts := append([]ReportOption{WithItemStartTimeClamp(false)}, test.opts...)
+		report, err := LoadXMLReport("testdata/mintime", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		suiteEnd := report.SuiteResult(0).EndTime
+		if d := suiteEnd.Sub(report.Suite(0).StartTime); d != test.suite {
+			t.Errorf("%s: expected suite duration %s, got %s", test.description, test.suite, d)
+		}
+		for j, want := range test.cases {
+			if d := report.TestCaseEndTime(0, j).Sub(report.TestCaseStartTime(0, j)); d != want {
+				t.Errorf("%s: expected %s duration %s, got %s", test.description, report.TestCase(0, j).Name, want, d)
+			}
+		}
+	}
+}
+
+func TestCounterReconciliation(t *testing.T) {
+	for _, reconcile := range []bool{false, true} {
+		report, err := LoadXMLReport("testdata/mismatch", WithCounterReconciliation(reconcile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]ExecutionStatus{
+			"c.Overclaimed":  ExecutionStatusFailed,
+			"c.Underclaimed": ExecutionStatusPassed,
+			"c.Consistent":   ExecutionStatusFailed,
+		}
+		if reconcile {
+			want["c.Overclaimed"] = ExecutionStatusPassed
+			want["c.Underclaimed"] = ExecutionStatusFailed
+		}
+		if count := report.SuitesCount(); count != len(want) {
+			t.Fatalf("expected %d suites, got %d", len(want), count)
+		}
+		for i := 0; i < report.SuitesCount(); i++ {
+			name := report.Suite(i).Name
+			if status := report.SuiteResult(i).Status; status != want[name] {
+				t.Errorf("reconcile %t: expected %s status %s, got %s", reconcile, name, want[name], status)
+			}
+		}
+	}
+}
+
+func TestLoadXMLReportFromReaderAggregate(t *testing.T) {
+	aggregate := readTestFile(t, "testdata/firstcase/queued.xml")
+	// pipe delivers document in chunks without size known upfront, as stdin does
+	pr, pw := io.Pipe()
+	go func() {
+		for len(aggregate) > 0 {
+			n := 64
+			if n > len(aggregate) {
+				n = len(aggregate)
+			}
+			if _, err := io.WriteString(pw, aggregate[:n]); err != nil {
+				return
+			}
+			aggregate = aggregate[n:]
+		}
+		pw.Close()
+	}()
+	report, err := LoadXMLReportFromReader(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "q.Queued q.Untimed"; got != want {
+		t.Errorf("expected aggregate suites %q, got %q", want, got)
+	}
+
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = stdinR
+	defer func() {
+		os.Stdin = stdin
+		stdinR.Close()
+	}()
+	piped := readTestFile(t, "testdata/firstcase/queued.xml")
+	go func() {
+		io.WriteString(stdinW, piped)
+		stdinW.Close()
+	}()
+	report, err = LoadXMLReportStdin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := suiteNames(report), "q.Queued q.Untimed"; got != want {
+		t.Errorf("expected stdin suites %q, got %q", want, got)
+	}
+}
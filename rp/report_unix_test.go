@@ -0,0 +1,53 @@
+//go:build unix
+
+package rp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestLoadXMLReportContextCanceledMidParse(t *testing.T) {
+	dir := t.TempDir()
+	// reading of the first file blocks on fifo until the context is canceled
+	fifo := filepath.Join(dir, "a.xml")
+	if err := syscall.Mkfifo(fifo, 0600); err != nil {
+		t.Skip(err)
+	}
+	suite := readTestFile(t, "testdata/properties/suite.xml")
+	if err := os.WriteFile(filepath.Join(dir, "b.xml"), []byte(suite), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	writeErr := make(chan error, 1)
+	go func() {
+		f, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+		if err != nil {
+			writeErr <- err
+			return
+		}
+		cancel()
+		_, err = f.WriteString(suite)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		writeErr <- err
+	}()
+
+	report, err := LoadXMLReportContext(ctx, dir)
+	if err := <-writeErr; err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if report != nil {
+		t.Error("expected no partial report on cancellation")
+	}
+}
@@ -3,7 +3,11 @@ package rp
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 )
 
 type TestItemType string
@@ -12,6 +16,9 @@ type LogLevel string
 type Mode string
 
 const (
+	// Version of rp client library, used in default User-Agent header
+	Version = "0.1.3"
+
 	// TimestampLayout can be used with time.Parse to create time.Time values from strings.
 	TimestampLayout = "2006-01-02T15:04:05.000Z"
 
@@ -48,42 +55,200 @@ const (
 	ModeDebug Mode = "DEBUG"
 	// ModeDefault - DEFAULT
 	ModeDefault Mode = "DEFAULT"
+
+	jsonContentType = "application/json;charset=utf-8"
+	requestIDHeader = "X-Request-Id"
+	// compressThreshold is minimal size of request body compressed with WithCompressedRequests
+	compressThreshold = 1024
+
+	// errorCodeFinishItemNotAllowed is Report Portal error code returned on finish of already finished item
+	errorCodeFinishItemNotAllowed = 40011
+)
+
+// String provides Report Portal wire value of TestItemType
+func (itemType TestItemType) String() string {
+	return string(itemType)
+}
+
+// MarshalText encodes TestItemType as its Report Portal wire value
+func (itemType TestItemType) MarshalText() ([]byte, error) {
+	return []byte(itemType), nil
+}
+
+// String provides Report Portal wire value of ExecutionStatus
+func (status ExecutionStatus) String() string {
+	return string(status)
+}
+
+// MarshalText encodes ExecutionStatus as its Report Portal wire value
+func (status ExecutionStatus) MarshalText() ([]byte, error) {
+	return []byte(status), nil
+}
+
+// String provides Report Portal wire value of LogLevel
+func (level LogLevel) String() string {
+	return string(level)
+}
+
+// MarshalText encodes LogLevel as its Report Portal wire value
+func (level LogLevel) MarshalText() ([]byte, error) {
+	return []byte(level), nil
+}
+
+// String provides Report Portal wire value of Mode
+func (mode Mode) String() string {
+	return string(mode)
+}
+
+// MarshalText encodes Mode as its Report Portal wire value
+func (mode Mode) MarshalText() ([]byte, error) {
+	return []byte(mode), nil
+}
+
+// Environment variables used by NewClientFromEnv
+const (
+	// EnvEndpoint - Report Portal API url, e.g. http://localhost:8080/api/v1
+	EnvEndpoint = "RP_ENDPOINT"
+	// EnvProject - Report Portal project name
+	EnvProject = "RP_PROJECT"
+	// EnvAPIKey - Report Portal user unique id (api key)
+	EnvAPIKey = "RP_API_KEY"
 )
 
+// NewClientFromEnv creates a RP Client configured by RP_ENDPOINT, RP_PROJECT and RP_API_KEY env vars,
+// error lists required env vars which are not set
+func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
+	missing := []string{}
+	values := map[string]string{}
+	for _, name := range []string{EnvEndpoint, EnvProject, EnvAPIKey} {
+		values[name] = os.Getenv(name)
+		if len(values[name]) == 0 {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) != 0 {
+		return nil, fmt.Errorf("missing required env vars: %s", strings.Join(missing, ", "))
+	}
+
+	c := NewClient(values[EnvEndpoint], values[EnvProject], values[EnvAPIKey], opts...)
+	return &c, nil
+}
+
 // NewClient creates a RP Client for specified project and user unique id
-func NewClient(apiURL, project, uuid string) Client {
+func NewClient(apiURL, project, uuid string, opts ...ClientOption) Client {
 	if len(project) == 0 {
 		log.Error("project could not be empty")
 	}
 	if len(uuid) == 0 {
 		log.Error("uuid could not be empty")
 	}
-	return Client{
-		baseURL:    joinURL(apiURL, project),
+	c := Client{
 		authBearer: "Bearer " + uuid,
 		http:       new(http.Client),
+		clock:      wallClock{},
+		userAgent:  "rp-client/" + Version,
+		unfinished: newUnfinishedItems(),
+		ordinals:   newItemOrdinals(),
+	}
+	for _, opt := range opts {
+		opt(&c)
 	}
+	c.baseURL = joinURL(apiURL, c.projectPrefix, project)
+	return c
 }
 
 // createNewRequest is used for building new http.Request to RP API with default headers
 // apiUrl should start from "/" e.g. '/launch'
-func (c *Client) createNewRequest(method string, apiURL string, payload []byte) (*http.Request, error) {
+func (c *Client) createNewRequest(method, apiURL, contentType, requestID string, payload []byte) (*http.Request, error) {
 	req, err := http.NewRequest(method, joinURL(c.baseURL, apiURL), bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Add("Authorization", c.authBearer)
-	req.Header.Add("Content-Type", "application/json;charset=utf-8")
-	return req, err
+	req.Header.Add("Content-Type", contentType)
+	req.Header.Add(requestIDHeader, requestID)
+	req.Header.Set("User-Agent", c.userAgent)
+	return req, nil
 }
 
-// request is used to send api request to rp
-func (c *Client) request(method, apiURL string, payload []byte) (*http.Response, error) {
-	req, err := c.createNewRequest(method, apiURL, payload)
-	if err != nil {
-		return nil, err
+// request is used to send api request to rp, retryable failures are retried up to configured retry attempts.
+// Every request is sent with unique X-Request-Id header kept the same for all its retries
+func (c *Client) request(method, apiURL, contentType string, payload []byte) (*http.Response, error) {
+	requestID := newRequestID()
+	started := time.Now()
+	body := payload
+	compressed := c.compressRequests && len(payload) > compressThreshold
+	if compressed {
+		var err error
+		payload, err = gzipPayload(payload)
+		if err != nil {
+			return nil, err
+		}
 	}
-	log.Debugf("rp request: %v", req)
-	resp, err := c.http.Do(req)
-	log.Debugf("rp responce: %v", resp)
-	return resp, err
+	for attempt := 1; ; attempt++ {
+		req, err := c.createNewRequest(method, apiURL, contentType, requestID, payload)
+		if err != nil {
+			return nil, err
+		}
+		if compressed {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		log.Debugf("rp request: %v", req)
+		start := time.Now()
+		resp, err := c.http.Do(req)
+		log.Debugf("rp responce: %v", resp)
+		if c.requestLogger != nil {
+			c.logRequest(req, resp, err, attempt, time.Since(start), body)
+		}
+
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if err != nil && !IsRetryable(err) {
+			return nil, err
+		}
+		if attempt > c.retryAttempts {
+			return resp, err
+		}
+		delay := c.retryDelay
+		if c.retryBackoff != nil {
+			delay = c.retryBackoff.delay(attempt)
+		}
+		if c.retryBudget > 0 && time.Since(started)+delay > c.retryBudget {
+			log.Warningf("rp request %s %s (%s) retry budget %s is exceeded", method, apiURL, requestID, c.retryBudget)
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+		log.Warningf("rp request %s %s (%s) failed, retry %d of %d", method, apiURL, requestID, attempt, c.retryAttempts)
+		time.Sleep(delay)
+	}
+}
+
+// logRequest passes request info to request logger
+func (c *Client) logRequest(req *http.Request, resp *http.Response, err error, attempt int, d time.Duration, body []byte) {
+	info := RequestInfo{
+		Method:    req.Method,
+		URL:       req.URL.String(),
+		RequestID: req.Header.Get(requestIDHeader),
+		Attempt:   attempt,
+		Duration:  d,
+		Err:       err,
+	}
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+	}
+	if c.logRequestBody {
+		info.Body = body
+	}
+	c.requestLogger(info)
+}
+
+// get request
+func (c *Client) get(apiURL string) (*http.Response, error) {
+	return c.request("GET", apiURL, jsonContentType, nil)
 }
 
 // post request
@@ -92,7 +257,7 @@ func (c *Client) post(apiURL string, body interface{}) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
-	return c.request("POST", apiURL, payload)
+	return c.request("POST", apiURL, jsonContentType, payload)
 }
 
 // put request
@@ -101,5 +266,5 @@ func (c *Client) put(apiURL string, body interface{}) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
-	return c.request("PUT", apiURL, payload)
+	return c.request("PUT", apiURL, jsonContentType, payload)
 }
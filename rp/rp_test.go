@@ -0,0 +1,568 @@
+package rp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeItem is test item started on fakeRP
+type fakeItem struct {
+	ID        string
+	ParentID  string
+	Name      string
+	Type      string
+	StartTime string
+	Tags      []string
+	CodeRef   string
+}
+
+// fakeLaunch is launch started on fakeRP
+type fakeLaunch struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	StartTime   string   `json:"start_time"`
+	Tags        []string `json:"tags"`
+}
+
+// fakeLog is log message sent to fakeRP
+type fakeLog struct {
+	ItemID  string `json:"item_id"`
+	Message string `json:"message"`
+	Level   string `json:"level"`
+}
+
+// fakeFile is log attachment sent to fakeRP
+type fakeFile struct {
+	Name    string
+	Content string
+}
+
+// fakeRP is minimal Report Portal API v1 server recording started launches, items and sent logs
+type fakeRP struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	launches []fakeLaunch
+	items    []fakeItem
+	// finished are statuses of finished launches and items by id
+	finished map[string]string
+	logs     []fakeLog
+	// batches are logs of every log request
+	batches [][]fakeLog
+	files   []fakeFile
+	// requestIDs are X-Request-Id headers of received requests
+	requestIDs []string
+	// failItem makes start of item with given name fail with internal server error
+	failItem func(name string) bool
+}
+
+func newFakeRP(t *testing.T) *fakeRP {
+	rp := &fakeRP{
+		finished: make(map[string]string),
+	}
+	rp.Server = httptest.NewServer(http.HandlerFunc(rp.handle))
+	t.Cleanup(rp.Close)
+	return rp
+}
+
+// client creates Client of fakeRP project
+func (rp *fakeRP) client(opts ...ClientOption) *Client {
+	c := NewClient(rp.URL, "project", "uuid", opts...)
+	return &c
+}
+
+func (rp *fakeRP) handle(w http.ResponseWriter, r *http.Request) {
+	apiURL := strings.TrimPrefix(r.URL.Path, "/project")
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.requestIDs = append(rp.requestIDs, r.Header.Get(requestIDHeader))
+	switch {
+	case r.Method == http.MethodPost && apiURL == "/launch":
+		var launch fakeLaunch
+		if err := json.NewDecoder(r.Body).Decode(&launch); err != nil {
+			rp.reply(w, http.StatusBadRequest, map[string]interface{}{"error_code": 4001, "message": err.Error()})
+			return
+		}
+		rp.launches = append(rp.launches, launch)
+		rp.reply(w, http.StatusCreated, ResponceID{ID: "launch"})
+	case r.Method == http.MethodPut && strings.HasSuffix(apiURL, "/finish"):
+		rp.finished[strings.TrimSuffix(strings.TrimPrefix(apiURL, "/launch/"), "/finish")] = readStatus(r)
+		rp.reply(w, http.StatusOK, FinishResult{ID: "launch", Number: 1})
+	case r.Method == http.MethodPost && strings.HasPrefix(apiURL, "/item"):
+		var item struct {
+			Name      string   `json:"name"`
+			Type      string   `json:"type"`
+			StartTime string   `json:"start_time"`
+			Tags      []string `json:"tags"`
+			CodeRef   string   `json:"codeRef"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+			rp.reply(w, http.StatusBadRequest, map[string]interface{}{"error_code": 4001, "message": err.Error()})
+			return
+		}
+		if rp.failItem != nil && rp.failItem(item.Name) {
+			rp.reply(w, http.StatusInternalServerError, map[string]interface{}{"error_code": 5000, "message": "boom"})
+			return
+		}
+		id := fmt.Sprintf("item%d", len(rp.items)+1)
+		rp.items = append(rp.items, fakeItem{
+			ID:        id,
+			ParentID:  strings.TrimPrefix(strings.TrimPrefix(apiURL, "/item"), "/"),
+			Name:      item.Name,
+			Type:      item.Type,
+			StartTime: item.StartTime,
+			Tags:      item.Tags,
+			CodeRef:   item.CodeRef,
+		})
+		rp.reply(w, http.StatusCreated, ResponceID{ID: id})
+	case r.Method == http.MethodPut && strings.HasPrefix(apiURL, "/item/"):
+		rp.finished[strings.TrimPrefix(apiURL, "/item/")] = readStatus(r)
+		rp.reply(w, http.StatusOK, map[string]string{"msg": "finished"})
+	case r.Method == http.MethodPost && apiURL == "/log":
+		if err := rp.readLogs(r); err != nil {
+			rp.reply(w, http.StatusBadRequest, map[string]interface{}{"error_code": 4001, "message": err.Error()})
+			return
+		}
+		rp.reply(w, http.StatusCreated, ResponceID{ID: fmt.Sprintf("log%d", len(rp.logs))})
+	default:
+		rp.reply(w, http.StatusNotFound, map[string]interface{}{"error_code": 4040, "message": "not found"})
+	}
+}
+
+// readLogs records log messages of single log or multipart log request with its files
+func (rp *fakeRP) readLogs(r *http.Request) error {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		var logMessage fakeLog
+		if err := json.NewDecoder(r.Body).Decode(&logMessage); err != nil {
+			return err
+		}
+		rp.logs = append(rp.logs, logMessage)
+		rp.batches = append(rp.batches, []fakeLog{logMessage})
+		return nil
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if part.FormName() == "json_request_part" {
+			var logs []fakeLog
+			if err := json.NewDecoder(part).Decode(&logs); err != nil {
+				return err
+			}
+			rp.logs = append(rp.logs, logs...)
+			rp.batches = append(rp.batches, logs)
+			continue
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		b, err := io.ReadAll(part)
+		if err != nil {
+			return err
+		}
+		rp.files = append(rp.files, fakeFile{Name: part.FileName(), Content: string(b)})
+	}
+}
+
+// readStatus provides status of finish request
+func readStatus(r *http.Request) string {
+	var result struct {
+		Status string `json:"status"`
+	}
+	json.NewDecoder(r.Body).Decode(&result)
+	return result.Status
+}
+
+// finishedStatus provides status launch or item is finished with and whether it is finished
+func (rp *fakeRP) finishedStatus(id string) (string, bool) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	status, ok := rp.finished[id]
+	return status, ok
+}
+
+func (rp *fakeRP) reply(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+// itemLogs provides messages of logs sent to item
+func (rp *fakeRP) itemLogs(itemID string) []string {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	var messages []string
+	for _, logMessage := range rp.logs {
+		if logMessage.ItemID == itemID {
+			messages = append(messages, logMessage.Message)
+		}
+	}
+	return messages
+}
+
+// children provides items started under given parent, top level items for empty parent
+func (rp *fakeRP) children(parentID string) []fakeItem {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	var items []fakeItem
+	for _, item := range rp.items {
+		if item.ParentID == parentID {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestIDUniquePerRequest(t *testing.T) {
+	report, err := LoadXMLReport("testdata/ordinal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	_, err = rp.client().Publish(report, &Launch{Name: "request id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	for _, requestID := range rp.requestIDs {
+		if !uuidPattern.MatchString(requestID) {
+			t.Errorf("expected UUID request id, got %q", requestID)
+		}
+		if seen[requestID] {
+			t.Errorf("request id %s is not unique", requestID)
+		}
+		seen[requestID] = true
+	}
+}
+
+func TestRequestIDKeptForRetries(t *testing.T) {
+	var requestIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs = append(requestIDs, r.Header.Get(requestIDHeader))
+		w.Header().Set("Content-Type", jsonContentType)
+		if len(requestIDs) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error_code":5000,"message":"unavailable"}`)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error_code":4001,"message":"bad launch"}`)
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "project", "uuid", WithRetry(2, time.Millisecond))
+	_, err := c.GetLaunch("launch")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if len(requestIDs) != 2 || requestIDs[0] != requestIDs[1] {
+		t.Fatalf("expected the same request id for retry, got %v", requestIDs)
+	}
+	if apiErr.RequestID != requestIDs[0] {
+		t.Errorf("expected APIError request id %s, got %s", requestIDs[0], apiErr.RequestID)
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	rp := newFakeRP(t)
+	t.Setenv(EnvEndpoint, rp.URL)
+	t.Setenv(EnvProject, "project")
+	t.Setenv(EnvAPIKey, "")
+	if _, err := NewClientFromEnv(); err == nil || !strings.Contains(err.Error(), EnvAPIKey) {
+		t.Fatalf("expected error listing %s, got %v", EnvAPIKey, err)
+	}
+
+	t.Setenv(EnvAPIKey, "uuid")
+	c, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if launchID := c.StartLaunch(&Launch{Name: "env"}); launchID == nil || launchID.ID != "launch" {
+		t.Errorf("expected launch started on env endpoint, got %v", launchID)
+	}
+}
+
+// fixedClock is Clock frozen at given time
+type fixedClock time.Time
+
+func (clock fixedClock) Now() time.Time {
+	return time.Time(clock)
+}
+
+func TestClockUsedForMissingTimes(t *testing.T) {
+	rp := newFakeRP(t)
+	now := time.Date(2017, 5, 5, 20, 3, 50, 0, time.UTC)
+	c := rp.client(WithClock(fixedClock(now)))
+	launch := &Launch{Name: "frozen"}
+	c.StartLaunch(launch)
+	if !launch.StartTime.Equal(now) {
+		t.Errorf("expected launch start time %s, got %s", now, launch.StartTime)
+	}
+	if len(rp.launches) != 1 || rp.launches[0].StartTime != now.Format(TimestampLayout) {
+		t.Errorf("expected launch started at %s, got %+v", now.Format(TimestampLayout), rp.launches)
+	}
+
+	c.StartTestItem("", &TestItem{Name: "unset", Type: TestItemTypeSuite})
+	c.StartTestItem("", &TestItem{Name: "set", Type: TestItemTypeSuite, StartTime: now.Add(time.Hour)})
+
+	want := []string{now.Format(TimestampLayout), now.Add(time.Hour).Format(TimestampLayout)}
+	if len(rp.items) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(rp.items))
+	}
+	for k, item := range rp.items {
+		if item.StartTime != want[k] {
+			t.Errorf("item %s: expected start time %s, got %s", item.Name, want[k], item.StartTime)
+		}
+	}
+}
+
+// statusServer provides server responding to every request with given status and error body,
+// counting received requests
+func statusServer(t *testing.T, statusCode int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", jsonContentType)
+		w.WriteHeader(statusCode)
+		fmt.Fprintf(w, `{"error_code":%d,"message":"%s"}`, statusCode*10, http.StatusText(statusCode))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestRetryTransientErrorsOnly(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		requests   int32
+		retryable  bool
+	}{
+		{http.StatusBadRequest, 1, false},
+		{http.StatusNotFound, 1, false},
+		{http.StatusTooManyRequests, 3, true},
+		{http.StatusServiceUnavailable, 3, true},
+	}
+	for _, test := range tests {
+		server, requests := statusServer(t, test.statusCode)
+		c := NewClient(server.URL, "project", "uuid", WithRetry(2, time.Millisecond))
+		_, err := c.GetLaunch("launch")
+		if n := atomic.LoadInt32(requests); n != test.requests {
+			t.Errorf("status %d: expected %d requests, got %d", test.statusCode, test.requests, n)
+		}
+		if retryable := IsRetryable(err); retryable != test.retryable {
+			t.Errorf("status %d: expected retryable %t, got %t for %v", test.statusCode, test.retryable, retryable, err)
+		}
+	}
+	if IsRetryable(nil) || IsRetryable(errors.New("permanent")) {
+		t.Error("expected nil and plain errors not to be retryable")
+	}
+}
+
+// recordedRequest is request received by recordServer
+type recordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// recordServer provides server recording received requests and responding to every request
+// with given status and json body
+func recordServer(t *testing.T, statusCode int, body string) (*httptest.Server, func() []recordedRequest) {
+	var mu sync.Mutex
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		requests = append(requests, recordedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: b})
+		mu.Unlock()
+		w.Header().Set("Content-Type", jsonContentType)
+		w.WriteHeader(statusCode)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}
+}
+
+func TestProjectPrefix(t *testing.T) {
+	server, requests := recordServer(t, http.StatusCreated, `{"id":"launch"}`)
+	for _, apiURL := range []string{server.URL + "/api/v1", server.URL + "/api/v1/"} {
+		c := NewClient(apiURL, "project", "uuid", WithProjectPrefix("tenant"))
+		c.StartLaunch(&Launch{Name: "tenant"})
+	}
+
+	for _, request := range requests() {
+		if want := "/api/v1/tenant/project/launch"; request.Path != want {
+			t.Errorf("expected path %s, got %s", want, request.Path)
+		}
+	}
+	if n := len(requests()); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	rp := newFakeRP(t)
+	c := rp.client(WithRedactor(RedactSecrets))
+	err := c.SendLogs([]*LogMessage{
+		{ItemID: "item", Level: LogLevelInfo, Message: "login with password=hunter2 and token: abc.def"},
+		{ItemID: "item", Level: LogLevelInfo, Message: "Authorization: Bearer eyJhbGciOi=="},
+		{ItemID: "item", Level: LogLevelInfo, Message: "nothing to hide"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"login with password=*** and token: ***",
+		"Authorization: Bearer ***",
+		"nothing to hide",
+	}
+	if len(rp.logs) != len(want) {
+		t.Fatalf("expected %d logs, got %d", len(want), len(rp.logs))
+	}
+	for k, logMessage := range rp.logs {
+		if logMessage.Message != want[k] {
+			t.Errorf("log %d: expected %q, got %q", k, want[k], logMessage.Message)
+		}
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	server, requests := recordServer(t, http.StatusCreated, `{"id":"launch"}`)
+	for _, opts := range [][]ClientOption{nil, {WithUserAgent("ci-uploader/2.0")}} {
+		c := NewClient(server.URL, "project", "uuid", opts...)
+		c.StartLaunch(&Launch{Name: "user agent"})
+	}
+
+	want := []string{"rp-client/" + Version, "ci-uploader/2.0"}
+	if len(requests()) != len(want) {
+		t.Fatalf("expected %d requests, got %d", len(want), len(requests()))
+	}
+	for k, request := range requests() {
+		if userAgent := request.Header.Get("User-Agent"); userAgent != want[k] {
+			t.Errorf("request %d: expected User-Agent %q, got %q", k, want[k], userAgent)
+		}
+	}
+}
+
+func TestCompressedRequests(t *testing.T) {
+	server, requests := recordServer(t, http.StatusCreated, `{"id":"launch"}`)
+	c := NewClient(server.URL, "project", "uuid", WithCompressedRequests(true))
+	description := strings.Repeat("large description ", 100)
+	c.StartLaunch(&Launch{Name: "large", Description: description})
+	c.StartLaunch(&Launch{Name: "small"})
+
+	recorded := requests()
+	if len(recorded) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(recorded))
+	}
+	if encoding := recorded[0].Header.Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("expected gzip encoded large request, got %q", encoding)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(recorded[0].Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var launch fakeLaunch
+	if err := json.NewDecoder(zr).Decode(&launch); err != nil {
+		t.Fatal(err)
+	}
+	if launch.Description != description {
+		t.Errorf("expected decompressed launch description, got %q", launch.Description)
+	}
+	// small request is not worth compressing
+	if encoding := recorded[1].Header.Get("Content-Encoding"); encoding != "" {
+		t.Errorf("expected small request sent as is, got %q encoding", encoding)
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	rp := newFakeRP(t)
+	var infos []RequestInfo
+	c := rp.client(WithRequestLogger(func(info RequestInfo) {
+		infos = append(infos, info)
+	}), WithRequestBodyLogging(true))
+	launchID := c.StartLaunch(&Launch{Name: "logged"})
+	if _, err := c.FinishLaunch(launchID.ID, &ExecutionResult{Status: ExecutionStatusPassed}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		method, url string
+		statusCode  int
+	}{
+		{http.MethodPost, rp.URL + "/project/launch", http.StatusCreated},
+		{http.MethodPut, rp.URL + "/project/launch/launch/finish", http.StatusOK},
+	}
+	if len(infos) != len(want) {
+		t.Fatalf("expected %d logged requests, got %d", len(want), len(infos))
+	}
+	for k, w := range want {
+		info := infos[k]
+		if info.Method != w.method || info.URL != w.url || info.StatusCode != w.statusCode || info.Attempt != 1 {
+			t.Errorf("request %d: expected %s %s %d, got %+v", k, w.method, w.url, w.statusCode, info)
+		}
+		if info.RequestID != rp.requestIDs[k] || info.Err != nil || len(info.Body) == 0 {
+			t.Errorf("request %d: expected request id %s with body and no error, got %+v", k, rp.requestIDs[k], info)
+		}
+	}
+
+	// every retry is logged
+	server, _ := statusServer(t, http.StatusServiceUnavailable)
+	infos = nil
+	retried := NewClient(server.URL, "project", "uuid", WithRetry(1, time.Millisecond), WithRequestLogger(func(info RequestInfo) {
+		infos = append(infos, info)
+	}))
+	retried.StartLaunch(&Launch{Name: "retried"})
+	if len(infos) != 2 || infos[1].Attempt != 2 || infos[1].StatusCode != http.StatusServiceUnavailable || infos[1].Body != nil {
+		t.Errorf("expected 2 attempts responded with 503 without body, got %+v", infos)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	server, requests := statusServer(t, http.StatusServiceUnavailable)
+	c := NewClient(server.URL, "project", "uuid", WithRetry(10, 50*time.Millisecond), WithRetryBudget(120*time.Millisecond))
+	started := time.Now()
+	_, err := c.GetLaunch("launch")
+	if !IsRetryable(err) {
+		t.Errorf("expected the last transient error, got %v", err)
+	}
+	// budget allows the first two retries only
+	if n := atomic.LoadInt32(requests); n < 1 || n > 3 {
+		t.Errorf("expected at most 3 requests within budget, got %d", n)
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("expected retries aborted by budget, took %s", elapsed)
+	}
+}
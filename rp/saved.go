@@ -0,0 +1,106 @@
+package rp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// savedReportVersion is format version of saved report, bumped on incompatible changes
+const savedReportVersion = 1
+
+// savedReport is JSON form of parsed report suites including their unexported state
+type savedReport struct {
+	Version          int               `json:"version"`
+	LaunchAttributes map[string]string `json:"launch_attributes,omitempty"`
+	// Raws holds every distinct raw suite XML once
+	Raws   [][]byte     `json:"raws,omitempty"`
+	Suites []savedSuite `json:"suites"`
+}
+
+type savedSuite struct {
+	Suite  xmlSuite `json:"suite"`
+	Parent int      `json:"parent"`
+	// Raw is index of suite raw XML in Raws plus one, 0 for suite without raw XML
+	Raw    int         `json:"raw,omitempty"`
+	Source string      `json:"source,omitempty"`
+	Cases  []savedCase `json:"cases"`
+}
+
+type savedCase struct {
+	Ordinal int      `json:"ordinal"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// Save writes parsed report as JSON, so it could be loaded by LoadSaved and published later without reparsing
+// the source files. Report options are not saved and should be passed to LoadSaved again
+func (report *XMLReport) Save(w io.Writer) error {
+	saved := savedReport{
+		Version:          savedReportVersion,
+		LaunchAttributes: report.launchAttributes,
+		Suites:           make([]savedSuite, 0, len(report.xmlSuites)),
+	}
+	raws := make(map[string]int)
+	for _, xSuite := range report.xmlSuites {
+		sSuite := savedSuite{
+			Suite:  xSuite,
+			Parent: xSuite.parent,
+			Source: xSuite.source,
+			Cases:  make([]savedCase, 0, len(xSuite.Cases)),
+		}
+		if len(xSuite.raw) != 0 {
+			k, ok := raws[string(xSuite.raw)]
+			if !ok {
+				saved.Raws = append(saved.Raws, xSuite.raw)
+				k = len(saved.Raws)
+				raws[string(xSuite.raw)] = k
+			}
+			sSuite.Raw = k
+		}
+		for _, xCase := range xSuite.Cases {
+			sSuite.Cases = append(sSuite.Cases, savedCase{
+				Ordinal: xCase.ordinal,
+				Tags:    xCase.tags,
+			})
+		}
+		saved.Suites = append(saved.Suites, sSuite)
+	}
+	return json.NewEncoder(w).Encode(saved)
+}
+
+// LoadSaved is used for loading report written by Save
+func LoadSaved(r io.Reader, opts ...ReportOption) (*XMLReport, error) {
+	var saved savedReport
+	err := json.NewDecoder(r).Decode(&saved)
+	if err != nil {
+		return nil, err
+	}
+	if saved.Version != savedReportVersion {
+		return nil, fmt.Errorf("unsupported saved report version: %d", saved.Version)
+	}
+
+	report := newXMLReport(opts)
+	report.launchAttributes = saved.LaunchAttributes
+	report.xmlSuites = make([]xmlSuite, 0, len(saved.Suites))
+	for _, sSuite := range saved.Suites {
+		xSuite := sSuite.Suite
+		if len(sSuite.Cases) != len(xSuite.Cases) {
+			return nil, fmt.Errorf("saved suite '%s' cases mismatch", xSuite.Name)
+		}
+		if sSuite.Raw < 0 || sSuite.Raw > len(saved.Raws) {
+			return nil, fmt.Errorf("saved suite '%s' raw xml %d is out of range", xSuite.Name, sSuite.Raw)
+		}
+		if sSuite.Raw != 0 {
+			xSuite.raw = saved.Raws[sSuite.Raw-1]
+		}
+		xSuite.parent = sSuite.Parent
+		xSuite.source = sSuite.Source
+		for j := range xSuite.Cases {
+			xSuite.Cases[j].ordinal = sSuite.Cases[j].Ordinal
+			xSuite.Cases[j].tags = sSuite.Cases[j].Tags
+		}
+		report.xmlSuites = append(report.xmlSuites, xSuite)
+	}
+	report.setCaseOffsets(report.xmlSuites)
+	return report, nil
+}
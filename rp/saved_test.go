@@ -0,0 +1,68 @@
+package rp
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLoadSavedRoundTrip(t *testing.T) {
+	report, err := LoadXMLReport("testdata/saved", WithRawXML(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	err = report.Save(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var saved savedReport
+	err = json.Unmarshal(b.Bytes(), &saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a.xml and b.xml have the same content, c.xml has single top level suite
+	if len(saved.Raws) != 2 {
+		t.Errorf("expected 2 distinct raw xml saved, got %d", len(saved.Raws))
+	}
+
+	loaded, err := LoadSaved(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual := func(what string, want, got interface{}) {
+		t.Helper()
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: expected %+v, got %+v", what, want, got)
+		}
+	}
+	assertEqual("suites count", report.SuitesCount(), loaded.SuitesCount())
+	assertEqual("launch attributes", report.LaunchAttributes(), loaded.LaunchAttributes())
+	assertEqual("summary", report.Summary(), loaded.Summary())
+	assertEqual("launch start time", report.LaunchStartTime(), loaded.LaunchStartTime())
+	assertEqual("launch end time", report.LaunchEndTime(), loaded.LaunchEndTime())
+	for i := 0; i < report.SuitesCount(); i++ {
+		assertEqual("suite", report.Suite(i), loaded.Suite(i))
+		assertEqual("suite result", report.SuiteResult(i), loaded.SuiteResult(i))
+		assertEqual("suite parent", report.SuiteParent(i), loaded.SuiteParent(i))
+		assertEqual("suite properties log", report.SuitePropertiesLog(i), loaded.SuitePropertiesLog(i))
+		assertEqual("suite raw xml", report.SuiteRawXML(i), loaded.SuiteRawXML(i))
+		assertEqual("test cases count", report.TesCaseCount(i), loaded.TesCaseCount(i))
+		for j := 0; j < report.TesCaseCount(i); j++ {
+			assertEqual("test case", report.TestCase(i, j), loaded.TestCase(i, j))
+			assertEqual("test case result", report.TestCaseResult(i, j), loaded.TestCaseResult(i, j))
+			assertEqual("test case ordinal", report.TestCaseOrdinal(i, j), loaded.TestCaseOrdinal(i, j))
+			assertEqual("test case logs", report.TestCaseLogs(i, j), loaded.TestCaseLogs(i, j))
+		}
+	}
+}
+
+func TestLoadSavedRawOutOfRange(t *testing.T) {
+	saved := `{"version":1,"suites":[{"suite":{"Name":"s"},"raw":2,"cases":[]}]}`
+	_, err := LoadSaved(bytes.NewBufferString(saved))
+	if err == nil {
+		t.Error("expected error for raw xml index out of range")
+	}
+}
@@ -0,0 +1,141 @@
+package rp
+
+import (
+	"context"
+	"errors"
+)
+
+// defaultChunkWindow is count of suites StreamXMLReport keeps for ordering unless set by WithChunkedDirScan
+const defaultChunkWindow = 100
+
+// StreamXMLReport is used for loading JUnit XML report from huge directory in chunks, so suites of all report files
+// are not kept in memory at once. Suites are kept in sliding window of WithChunkedDirScan suites and sent in chunks
+// ordered by configured suite order, so suites are ordered across chunks unless they are more than window suites apart.
+// Split suites are merged only within chunk. Both channels are closed when directory is scanned or ctx is done,
+// error channel receives scan error if any
+func StreamXMLReport(ctx context.Context, dirName string, opts ...ReportOption) (<-chan *XMLReport, <-chan error) {
+	chunks := make(chan *XMLReport)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(chunks)
+		err := streamXMLReport(ctx, dirName, opts, chunks)
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return chunks, errs
+}
+
+// streamXMLReport sends suites of report files in window sized chunks
+func streamXMLReport(ctx context.Context, dirName string, opts []ReportOption, chunks chan<- *XMLReport) error {
+	base := newXMLReport(opts)
+	window := base.chunkWindow
+	if window <= 0 {
+		window = defaultChunkWindow
+	}
+
+	files, infos, err := reportFilePaths(ctx, dirName, isXMLFile)
+	if err != nil {
+		return err
+	}
+
+	send := func(xSuites []xmlSuite) error {
+		if len(xSuites) == 0 {
+			return nil
+		}
+		chunk := newXMLReport(opts)
+		chunk.launchAttributes = base.LaunchAttributes()
+		chunk.setSuites(xSuites)
+		select {
+		case chunks <- chunk:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	pending := make([]xmlSuite, 0, 2*window)
+	for i := range files {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		reportFile := base.readReportFile(files[i], infos[i], decodeXMLReportFile)
+		if reportFile == nil {
+			continue
+		}
+		pending = append(pending, reportFile.suites...)
+
+		if len(pending) >= 2*window {
+			base.sortSuites(pending)
+			n := len(pending) - window
+			err := send(append([]xmlSuite(nil), pending[:n]...))
+			if err != nil {
+				return err
+			}
+			pending = append(pending[:0], pending[n:]...)
+			// timestamps of sent suites are not needed anymore, so cache does not grow with files count
+			base.timestamps = newTimestampCache()
+		}
+	}
+	base.sortSuites(pending)
+	return send(pending)
+}
+
+// PublishStream uploads report chunks, e.g. sent by StreamXMLReport, to Report Portal as single new launch.
+// Launch start time defaults to first chunk launch start time, launch is finished at latest chunk launch end time.
+// Launch attributes, name template and item options are applied as by Publish using the first chunk, while
+// default description, single suite flattening and assertions attribute are not supported since the whole report
+// is not known at launch start. Upload errors are handled as by Publish. Producer of chunks should be cancelled
+// by caller once PublishStream returns error. Launch is not changed, defaults and tags are applied to its copy
+func (c *Client) PublishStream(chunks <-chan *XMLReport, launch *Launch, opts ...PublishOption) (*FinishResult, error) {
+	cfg := &publishConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	finishResult, err := c.publishStream(chunks, copyLaunch(launch), cfg)
+	if err != nil {
+		if len(cfg.launchID) != 0 {
+			c.finishLaunchUnfinished(cfg.launchID, ExecutionStatusFailed)
+		}
+		return finishResult, err
+	}
+	return finishResult, nil
+}
+
+// publishStream uploads report chunks as new launch
+func (c *Client) publishStream(chunks <-chan *XMLReport, launch *Launch, cfg *publishConfig) (*FinishResult, error) {
+	first, ok := <-chunks
+	if !ok || first.SuitesCount() == 0 {
+		return nil, errors.New("report has no suites")
+	}
+	if launch.StartTime.IsZero() {
+		launch.StartTime = first.LaunchStartTime()
+	}
+	cfg.prepareLaunch(first, launch)
+
+	launchID, err := c.startPublishedLaunch(launch, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime := first.LaunchEndTime()
+	for chunk := first; chunk != nil; chunk = <-chunks {
+		err := c.publishSuites(chunk, launchID, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if chunkEnd := chunk.LaunchEndTime(); chunkEnd.After(endTime) {
+			endTime = chunkEnd
+		}
+	}
+
+	finishResult, err := c.FinishLaunch(launchID, &ExecutionResult{
+		EndTime: endTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return finishResult, cfg.err()
+}
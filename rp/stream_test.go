@@ -0,0 +1,150 @@
+package rp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writeSuiteFiles writes n single suite report files to dir, suites start a second apart, but every pair of
+// adjacent files is written in reverse start order
+func writeSuiteFiles(tb testing.TB, dir string, n int) {
+	start := time.Date(2017, 5, 5, 20, 0, 0, 0, time.UTC)
+	for k := 0; k < n; k++ {
+		s := k ^ 1
+		if s >= n {
+			s = k
+		}
+		content := fmt.Sprintf(`<testsuite name="Suite%04d" package="p" timestamp="%s" time="1" tests="1">`+
+			`<testcase name="case" classname="p.Suite%04d" time="1"/></testsuite>`,
+			s, start.Add(time.Duration(s)*time.Second).Format("2006-01-02T15:04:05"), s)
+		err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("TEST-%04d.xml", k)), []byte(content), 0644)
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func TestStreamXMLReportChunks(t *testing.T) {
+	const files, window = 250, 10
+	dir := t.TempDir()
+	writeSuiteFiles(t, dir, files)
+
+	chunks, errs := StreamXMLReport(context.Background(), dir, WithChunkedDirScan(window))
+	var count, chunkCount int
+	var last time.Time
+	for chunk := range chunks {
+		chunkCount++
+		// suites kept in memory are bounded by window regardless of files count
+		if n := chunk.SuitesCount(); n > 2*window {
+			t.Errorf("chunk %d: expected at most %d suites, got %d", chunkCount, 2*window, n)
+		}
+		for i := 0; i < chunk.SuitesCount(); i++ {
+			start := chunk.Suite(i).StartTime
+			if start.Before(last) {
+				t.Errorf("suite %s started at %s is sent after suite started at %s", chunk.Suite(i).Name, start, last)
+			}
+			last = start
+			count++
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if count != files {
+		t.Errorf("expected %d suites, got %d", files, count)
+	}
+	if chunkCount < files/(2*window) {
+		t.Errorf("expected suites streamed in at least %d chunks, got %d", files/(2*window), chunkCount)
+	}
+}
+
+// liveHeap provides heap size after garbage collection
+func liveHeap() int64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.HeapAlloc)
+}
+
+func TestStreamXMLReportBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes many report files")
+	}
+	const files, window = 2000, 10
+	dir := t.TempDir()
+	writeSuiteFiles(t, dir, files)
+
+	baseline := liveHeap()
+	report, err := LoadXMLReport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := liveHeap() - baseline
+	runtime.KeepAlive(report)
+	report = nil
+
+	chunks, errs := StreamXMLReport(context.Background(), dir, WithChunkedDirScan(window))
+	// the first sample includes directory listing, later ones should not grow with count of streamed suites
+	var first, peak int64
+	k := 0
+	for range chunks {
+		if k++; k%10 == 0 {
+			live := liveHeap()
+			if first == 0 {
+				first = live
+			}
+			if live > peak {
+				peak = live
+			}
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if growth := peak - first; growth > loaded/20 {
+		t.Errorf("expected heap to stay bounded while streaming, it grew by %d bytes, loaded report keeps %d", growth, loaded)
+	}
+}
+
+func TestPublishStream(t *testing.T) {
+	dir := t.TempDir()
+	writeSuiteFiles(t, dir, 25)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chunks, errs := StreamXMLReport(ctx, dir, WithChunkedDirScan(4))
+	rp := newFakeRP(t)
+	_, err := rp.client().PublishStream(chunks, &Launch{Name: "stream"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rp.children("")); n != 25 {
+		t.Errorf("expected 25 suites published, got %d", n)
+	}
+	if _, ok := rp.finishedStatus("launch"); !ok {
+		t.Error("expected launch to be finished")
+	}
+}
+
+func BenchmarkStreamXMLReport(b *testing.B) {
+	dir := b.TempDir()
+	writeSuiteFiles(b, dir, 500)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		chunks, errs := StreamXMLReport(context.Background(), dir, WithChunkedDirScan(20))
+		for range chunks {
+		}
+		if err := <-errs; err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package rp
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Summary of test results of one or several reports based on suite counters
+type Summary struct {
+	Suites   int
+	Tests    int
+	Failures int
+	Errors   int
+	Skipped  int
+	Duration time.Duration
+
+	// Reports holds per report summaries, it is set only by SummarizeAll
+	Reports []Summary
+}
+
+// Summary provides test results totals for the report, nested suites are counted by their top level suites
+func (report *XMLReport) Summary() Summary {
+	summary := Summary{
+		Suites: len(report.xmlSuites),
+	}
+	for _, xSuite := range report.xmlSuites {
+		if xSuite.parent != 0 {
+			continue
+		}
+		summary.Tests += xSuite.Tests
+		summary.Failures += xSuite.Failures
+		summary.Errors += xSuite.Errors
+		summary.Skipped += xSuite.Skipped
+		summary.Duration += xSuite.Time.duration()
+	}
+	return summary
+}
+
+// SummarizeAll provides combined totals of several reports with per report breakdown,
+// unlike merging, reports suites are kept apart so each report could still be published as its own launch
+func SummarizeAll(reports ...*XMLReport) *Summary {
+	total := &Summary{
+		Reports: make([]Summary, 0, len(reports)),
+	}
+	for _, report := range reports {
+		summary := report.Summary()
+		total.Suites += summary.Suites
+		total.Tests += summary.Tests
+		total.Failures += summary.Failures
+		total.Errors += summary.Errors
+		total.Skipped += summary.Skipped
+		total.Duration += summary.Duration
+		total.Reports = append(total.Reports, summary)
+	}
+	return total
+}
+
+// ReportStats holds report totals in one place instead of calling separate count methods
+type ReportStats struct {
+	Suites   int
+	Tests    int
+	Failures int
+	Errors   int
+	Skipped  int
+	Passed   int
+	Duration time.Duration
+}
+
+// Stats provides report totals, tests which are neither failed, errored nor skipped are counted as passed
+func (report *XMLReport) Stats() ReportStats {
+	summary := report.Summary()
+	stats := ReportStats{
+		Suites:   summary.Suites,
+		Tests:    summary.Tests,
+		Failures: summary.Failures,
+		Errors:   summary.Errors,
+		Skipped:  summary.Skipped,
+		Duration: summary.Duration,
+	}
+	stats.Passed = stats.Tests - stats.Failures - stats.Errors - stats.Skipped
+	if stats.Passed < 0 {
+		stats.Passed = 0
+	}
+	return stats
+}
+
+// TotalAssertions provides sum of test case assertions counts, test cases without assertions attribute count as zero
+func (report *XMLReport) TotalAssertions() int {
+	total := 0
+	for _, xSuite := range report.xmlSuites {
+		for _, xCase := range xSuite.Cases {
+			total += xCase.Assertions
+		}
+	}
+	return total
+}
+
+// CountBy provides test cases counts grouped by key derived from suite name as provided by Suite, normalized
+// class name, test case name and status, e.g. to count cases by class or status
+func (report *XMLReport) CountBy(key func(suiteName, className, caseName string, status ExecutionStatus) string) map[string]int {
+	counts := make(map[string]int)
+	for i, xSuite := range report.xmlSuites {
+		suiteName := report.Suite(i).Name
+		for j, xCase := range xSuite.Cases {
+			counts[key(suiteName, report.TestCaseClassName(i, j), xCase.Name, report.TestCaseResult(i, j).Status)]++
+		}
+	}
+	return counts
+}
+
+// DurationHistogramOverflow is DurationHistogram bucket of test cases longer than the largest bucket bound
+const DurationHistogramOverflow = time.Duration(math.MaxInt64)
+
+// DurationHistogram provides test cases counts by duration buckets given by upper bounds: test case is counted
+// in the bucket of the smallest bound not less than its time, cases longer than every bound are counted
+// in DurationHistogramOverflow bucket. Every given bucket is present in result, even with zero count
+func (report *XMLReport) DurationHistogram(buckets []time.Duration) map[time.Duration]int {
+	bounds := append([]time.Duration(nil), buckets...)
+	sort.Slice(bounds, func(i, j int) bool {
+		return bounds[i] < bounds[j]
+	})
+
+	histogram := make(map[time.Duration]int, len(bounds)+1)
+	for _, bound := range bounds {
+		histogram[bound] = 0
+	}
+	for _, xSuite := range report.xmlSuites {
+		for _, xCase := range xSuite.Cases {
+			d := xCase.Time.duration()
+			k := sort.Search(len(bounds), func(k int) bool {
+				return bounds[k] >= d
+			})
+			if k == len(bounds) {
+				histogram[DurationHistogramOverflow]++
+				continue
+			}
+			histogram[bounds[k]]++
+		}
+	}
+	return histogram
+}
@@ -0,0 +1,84 @@
+package rp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCountBy(t *testing.T) {
+	report, err := LoadXMLReport("testdata/transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byStatus := report.CountBy(func(suiteName, className, caseName string, status ExecutionStatus) string {
+		return status.String()
+	})
+	want := map[string]int{"PASSED": 3, "FAILED": 2, "SKIPPED": 1}
+	if !reflect.DeepEqual(byStatus, want) {
+		t.Errorf("expected counts by status %v, got %v", want, byStatus)
+	}
+
+	byClass := report.CountBy(func(suiteName, className, caseName string, status ExecutionStatus) string {
+		return className
+	})
+	want = map[string]int{"t.Api": 3, "t.Auth": 1, "t.Ui": 2}
+	if !reflect.DeepEqual(byClass, want) {
+		t.Errorf("expected counts by class %v, got %v", want, byClass)
+	}
+}
+
+func TestDurationHistogram(t *testing.T) {
+	report, err := LoadXMLReport("testdata/durations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// buckets are given unsorted, bound itself belongs to its bucket
+	histogram := report.DurationHistogram([]time.Duration{time.Second, 100 * time.Millisecond, time.Minute, 10 * time.Second})
+	want := map[time.Duration]int{
+		100 * time.Millisecond:    3,
+		time.Second:               1,
+		10 * time.Second:          1,
+		time.Minute:               0,
+		DurationHistogramOverflow: 1,
+	}
+	if !reflect.DeepEqual(histogram, want) {
+		t.Errorf("expected histogram %v, got %v", want, histogram)
+	}
+}
+
+func TestSummarizeAll(t *testing.T) {
+	transform, err := LoadXMLReport("testdata/transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	skipped, err := LoadXMLReport("testdata/skipped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := SummarizeAll(transform, skipped)
+
+	want := []Summary{
+		{Suites: 3, Tests: 5, Failures: 1, Errors: 1, Skipped: 1, Duration: 6 * time.Second},
+		{Suites: 3, Tests: 4, Skipped: 3, Duration: time.Second},
+	}
+	if !reflect.DeepEqual(total.Reports, want) {
+		t.Errorf("expected per report summaries %+v, got %+v", want, total.Reports)
+	}
+	combined := Summary{Suites: 6, Tests: 9, Failures: 1, Errors: 1, Skipped: 4, Duration: 7 * time.Second, Reports: want}
+	if !reflect.DeepEqual(*total, combined) {
+		t.Errorf("expected combined summary %+v, got %+v", combined, *total)
+	}
+}
+
+func TestStats(t *testing.T) {
+	report, err := LoadXMLReport("testdata/transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nested suite is counted by its top level suite
+	want := ReportStats{Suites: 3, Tests: 5, Failures: 1, Errors: 1, Skipped: 1, Passed: 2, Duration: 6 * time.Second}
+	if stats := report.Stats(); stats != want {
+		t.Errorf("expected stats %+v, got %+v", want, stats)
+	}
+}
@@ -0,0 +1,120 @@
+package rp
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// tapSuiteName is name of the suite for TAP stream test cases
+const tapSuiteName = "tap"
+
+var (
+	tapTestLine = regexp.MustCompile(`^(not )?ok\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*((?i:SKIP|TODO))\S*\s*(.*))?$`)
+	tapPlanLine = regexp.MustCompile(`^1\.\.(\d+)`)
+)
+
+// LoadTAPReport is used for loading TAP (Test Anything Protocol) version 13 stream as report with single suite.
+// Not ok test is failed, SKIP and TODO directives of any case mark test as skipped, YAML diagnostic block following
+// the test becomes failure details. Indented subtests are not reported, their parent test line summarizes them.
+// TAP has no timings, so suite starts at load time and every test case takes zero time
+func LoadTAPReport(r io.Reader, opts ...ReportOption) (*XMLReport, error) {
+	xSuite := xmlSuite{
+		Name:      tapSuiteName,
+		TimeStamp: time.Now().UTC().Format(TimestampLayout),
+		Cases:     make([]xmlTest, 0),
+	}
+
+	var yaml []string
+	inYAML := false
+	// afterCase is set just after top level test line, which could be followed by its YAML diagnostic block
+	afterCase := false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if inYAML {
+			if trimmed == "..." {
+				inYAML = false
+				setTAPDiagnostic(&xSuite, yaml)
+				continue
+			}
+			yaml = append(yaml, line)
+			continue
+		}
+		if trimmed == "---" && afterCase {
+			inYAML = true
+			afterCase = false
+			yaml = nil
+			continue
+		}
+		afterCase = false
+		if strings.HasPrefix(trimmed, "Bail out!") {
+			break
+		}
+		if tapPlanLine.MatchString(trimmed) {
+			continue
+		}
+
+		// subtests are indented, and so are their plans and YAML blocks
+		if trimmed != strings.TrimRight(line, " \t") {
+			continue
+		}
+		m := tapTestLine.FindStringSubmatch(trimmed)
+		if m == nil {
+			continue
+		}
+		xCase := xmlTest{
+			Name:    m[3],
+			ordinal: len(xSuite.Cases),
+		}
+		if len(xCase.Name) == 0 {
+			xCase.Name = "test " + m[2]
+		}
+		switch {
+		case len(m[4]) != 0:
+			xCase.Skipped = &xmlSkipped{
+				Message: strings.TrimSpace(m[4] + " " + m[5]),
+			}
+			xSuite.Skipped++
+		case len(m[1]) != 0:
+			xCase.Failure = &xmlFailure{
+				Message: xCase.Name,
+			}
+			xSuite.Failures++
+		}
+		xSuite.Tests++
+		xSuite.Cases = append(xSuite.Cases, xCase)
+		afterCase = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if inYAML {
+		setTAPDiagnostic(&xSuite, yaml)
+	}
+
+	report := newXMLReport(opts)
+	report.setSuites([]xmlSuite{xSuite})
+	return report, nil
+}
+
+// setTAPDiagnostic sets YAML diagnostic block as failure details of the last failed test case
+func setTAPDiagnostic(xSuite *xmlSuite, yaml []string) {
+	xCase := &xSuite.Cases[len(xSuite.Cases)-1]
+	if xCase.Failure == nil {
+		return
+	}
+	xCase.Failure.Details = strings.Join(yaml, "\n")
+	for _, line := range yaml {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "message:") {
+			xCase.Failure.Message = strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "message:")), `"'`)
+			break
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package rp
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadTAPReportSubtests(t *testing.T) {
+	f, err := os.Open("testdata/tap/subtests.tap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	report, err := LoadTAPReport(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name   string
+		status ExecutionStatus
+	}{
+		{"parser", ExecutionStatusFailed},
+		{"lexer", ExecutionStatusPassed},
+		{"formatter", ExecutionStatusSkipped},
+		{"printer", ExecutionStatusSkipped},
+	}
+	if n := report.TesCaseCount(0); n != len(want) {
+		t.Fatalf("expected %d top level cases, got %d", len(want), n)
+	}
+	for j, w := range want {
+		if name := report.TestCase(0, j).Name; name != w.name {
+			t.Errorf("case %d: expected name %s, got %s", j, w.name, name)
+		}
+		if status := report.TestCaseResult(0, j).Status; status != w.status {
+			t.Errorf("case %s: expected status %s, got %s", w.name, w.status, status)
+		}
+	}
+	if message := report.TestCaseFailure(0, 0).Message; message != "1 of 2 subtests failed" {
+		t.Errorf("expected parent test failure message from its own diagnostic, got %q", message)
+	}
+}
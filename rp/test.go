@@ -1,65 +1,150 @@
 package rp
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"sync"
 )
 
 // StartTestItem is used to create new test suite for specified launch
 func (c *Client) StartTestItem(parentItemID string, testItem *TestItem) (testItemID *ResponceID) {
+	if testItem.StartTime.IsZero() {
+		testItem.StartTime = c.clock.Now()
+	}
+	if testItem.Ordinal > 0 && c.ordinals != nil {
+		testItem.StartTime = c.ordinals.start(ordinalParentKey(parentItemID, testItem.LaunchID), testItem.Ordinal, testItem.StartTime)
+	}
+
 	apiURL := "/item"
 	if len(parentItemID) > 0 {
 		apiURL = apiURL + "/" + parentItemID
 	}
 
-	resp, err := c.post(apiURL, testItem)
-	defer resp.Body.Close()
+	payload := testItem
+	if c.typeMapper != nil {
+		mapped := *testItem
+		mapped.Type = TestItemType(c.typeMapper.MapType(testItem.Type))
+		payload = &mapped
+	}
 
+	resp, err := c.post(apiURL, payload)
 	if err != nil {
 		log.Error(err)
 		return
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusCreated {
 		err := json.NewDecoder(resp.Body).Decode(&testItemID)
 		if err != nil {
 			log.Error(err)
+		} else {
+			c.unfinished.start(testItemID.ID, testItem.LaunchID, false)
 		}
 	} else {
-		log.Error(decodeError(resp.Body))
+		log.Error(decodeError(resp))
 	}
 	return
 }
 
-// FinishTestItem update specified test item to passed (completed state)
-func (c *Client) FinishTestItem(testItemID string, result *ExecutionResult) {
+// FinishTestItem update specified test item to passed (completed state),
+// item which is already finished is treated as successfully finished
+func (c *Client) FinishTestItem(testItemID string, result *ExecutionResult) error {
 	if len(testItemID) == 0 {
-		log.Error("suiteID could not be empty")
-		return
+		return errors.New("testItemID could not be empty")
+	}
+	if result.EndTime.IsZero() {
+		result.EndTime = c.clock.Now()
+	}
+	if c.logLimit != nil {
+		if tail := c.logLimit.drain(testItemID); len(tail) != 0 {
+			err := c.postLogs(tail, nil)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	resp, err := c.put("/item/"+testItemID, result)
-	defer resp.Body.Close()
-
 	if err != nil {
-		log.Error(err)
-		return
+		return err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		log.Error(decodeError(resp.Body))
+		err := decodeError(resp)
+		if !isItemAlreadyFinished(err) {
+			return err
+		}
+		log.Warningf("test item %s is already finished", testItemID)
+	}
+	c.unfinished.finish(testItemID)
+	if c.ordinals != nil {
+		c.ordinals.finish(testItemID)
 	}
+	return nil
 }
 
-// SendMesssage create new log entry for provided item
+// finishConcurrency is count of concurrent requests sent by FinishTestItemsConcurrently
+const finishConcurrency = 4
+
+// FinishTestItemsConcurrently finishes several test items, e.g. test steps of suite. It is not a bulk finish:
+// Report Portal API v1 has no bulk finish request, so every item is finished by its own FinishTestItem request
+// sent concurrently with others. Items are finished in no particular order, so results should not contain both
+// parent item and its children. Nothing is finished when any result is nil, otherwise first error is returned
+func (c *Client) FinishTestItemsConcurrently(results map[string]*ExecutionResult) error {
+	for id, result := range results {
+		if result == nil {
+			return fmt.Errorf("result of test item %s could not be nil", id)
+		}
+	}
+
+	ids := make(chan string)
+	errs := make(chan error, len(results))
+	var wg sync.WaitGroup
+	for k := 0; k < finishConcurrency && k < len(results); k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				errs <- c.FinishTestItem(id, results[id])
+			}
+		}()
+	}
+	for id := range results {
+		ids <- id
+	}
+	close(ids)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendMesssage create new log entry for provided item, nil id is returned for log entry held back by WithLogLimit
 func (c *Client) SendMesssage(lgoMessage *LogMessage) (messageID *ResponceID) {
-	resp, err := c.post("/log", lgoMessage)
-	defer resp.Body.Close()
+	prepared := c.prepareLogMessage(lgoMessage)
+	if c.logLimit != nil && len(c.logLimit.admit([]*LogMessage{prepared})) == 0 {
+		log.Debugf("log message of item %s is held back by log limit", prepared.ItemID)
+		return
+	}
 
+	resp, err := c.post("/log", prepared)
 	if err != nil {
 		log.Error(err)
 		return
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusCreated {
 		err := json.NewDecoder(resp.Body).Decode(&messageID)
@@ -67,7 +152,91 @@ func (c *Client) SendMesssage(lgoMessage *LogMessage) (messageID *ResponceID) {
 			log.Error(err)
 		}
 	} else {
-		log.Error(decodeError(resp.Body))
+		log.Error(decodeError(resp))
 	}
 	return
 }
+
+// SendLogs create new log entries for provided items in single batch request
+func (c *Client) SendLogs(logMessages []*LogMessage) error {
+	if len(logMessages) == 0 {
+		return nil
+	}
+	prepared := make([]*LogMessage, len(logMessages))
+	for i, logMessage := range logMessages {
+		prepared[i] = c.prepareLogMessage(logMessage)
+	}
+	if c.logLimit != nil {
+		prepared = c.logLimit.admit(prepared)
+		if len(prepared) == 0 {
+			return nil
+		}
+	}
+
+	return c.postLogs(prepared, nil)
+}
+
+// SendAttachment create new log entry with attached file for provided item
+func (c *Client) SendAttachment(logMessage *LogMessage, attachment *Attachment) error {
+	prepared := c.prepareLogMessage(logMessage)
+	prepared.File = &LogFile{Name: attachment.Name}
+	return c.postLogs([]*LogMessage{prepared}, []*Attachment{attachment})
+}
+
+// postLogs sends prepared log messages with their attachments as multipart request
+func (c *Client) postLogs(logMessages []*LogMessage, attachments []*Attachment) error {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="json_request_part"`)
+	h.Set("Content-Type", jsonContentType)
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(part).Encode(logMessages)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, attachment.Name))
+		h.Set("Content-Type", attachment.ContentType)
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		_, err = part.Write(attachment.Content)
+		if err != nil {
+			return err
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.request("POST", "/log", w.FormDataContentType(), body.Bytes())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return decodeError(resp)
+	}
+	return nil
+}
+
+// prepareLogMessage creates copy of log message to send, with current client clock time
+// when time is empty and with message scrubbed by redactor
+func (c *Client) prepareLogMessage(logMessage *LogMessage) *LogMessage {
+	prepared := *logMessage
+	if prepared.Time.IsZero() {
+		prepared.Time = c.clock.Now()
+	}
+	if c.redactor != nil {
+		prepared.Message = c.redactor(prepared.Message)
+	}
+	return &prepared
+}
@@ -0,0 +1,115 @@
+package rp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFinishTestItemAlreadyFinished(t *testing.T) {
+	server := jsonServer(t, http.StatusNotAcceptable, `{"error_code":40011,"message":"Finish test item is not allowed. Test item 'item' has status 'PASSED'"}`)
+	c := NewClient(server.URL, "project", "uuid")
+	if err := c.FinishTestItem("item", &ExecutionResult{Status: ExecutionStatusPassed}); err != nil {
+		t.Errorf("expected already finished item to be finished without error, got %v", err)
+	}
+
+	server = jsonServer(t, http.StatusNotFound, `{"error_code":40401,"message":"Test item 'item' not found"}`)
+	c = NewClient(server.URL, "project", "uuid")
+	if err := c.FinishTestItem("item", &ExecutionResult{Status: ExecutionStatusPassed}); err == nil {
+		t.Error("expected error for other finish failures")
+	}
+}
+
+func TestFinishTestItemsConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight, requests int
+	finished := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		requests++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		finished[strings.TrimPrefix(r.URL.Path, "/project/item/")] = readStatus(r)
+		mu.Unlock()
+		// keep requests overlapping
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", jsonContentType)
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code":40401,"message":"not found"}`)
+			return
+		}
+		fmt.Fprint(w, `{"msg":"finished"}`)
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "project", "uuid")
+	results := make(map[string]*ExecutionResult)
+	for k := 0; k < 3*finishConcurrency; k++ {
+		results[fmt.Sprintf("item%d", k)] = &ExecutionResult{Status: ExecutionStatusPassed}
+	}
+	if err := c.FinishTestItemsConcurrently(results); err != nil {
+		t.Fatal(err)
+	}
+	if len(finished) != len(results) {
+		t.Errorf("expected %d finished items, got %d", len(results), len(finished))
+	}
+	if maxInFlight < 2 || maxInFlight > finishConcurrency {
+		t.Errorf("expected concurrent finish requests up to %d, got %d", finishConcurrency, maxInFlight)
+	}
+
+	results["missing"] = &ExecutionResult{Status: ExecutionStatusFailed}
+	if err := c.FinishTestItemsConcurrently(results); err == nil {
+		t.Error("expected error of missing item")
+	}
+
+	sent := requests
+	results["nil"] = nil
+	if err := c.FinishTestItemsConcurrently(results); err == nil {
+		t.Error("expected error of nil result")
+	}
+	if requests != sent {
+		t.Errorf("expected no item finished with nil result, got %d requests", requests-sent)
+	}
+}
+
+// lowercaseTypes maps test item types to lowercase, as expected by older servers
+type lowercaseTypes struct{}
+
+func (lowercaseTypes) MapType(itemType TestItemType) string {
+	return strings.ToLower(string(itemType))
+}
+
+func TestTypeMapper(t *testing.T) {
+	rp := newFakeRP(t)
+	c := rp.client(WithTypeMapper(lowercaseTypes{}))
+	suite := &TestItem{Name: "suite", Type: TestItemTypeSuite}
+	suiteID := c.StartTestItem("", suite)
+	if suiteID == nil {
+		t.Fatal("could not start suite")
+	}
+	c.StartTestItem(suiteID.ID, &TestItem{Name: "step", Type: TestItemTypeStep})
+
+	want := map[string]string{"suite": "suite", "step": "step"}
+	if len(rp.items) != len(want) {
+		t.Fatalf("expected %d items, got %+v", len(want), rp.items)
+	}
+	for _, item := range rp.items {
+		if item.Type != want[item.Name] {
+			t.Errorf("expected %s type %q sent, got %q", item.Name, want[item.Name], item.Type)
+		}
+	}
+	if suite.Type != TestItemTypeSuite {
+		t.Errorf("expected started test item type to be kept, got %s", suite.Type)
+	}
+}
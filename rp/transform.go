@@ -0,0 +1,141 @@
+package rp
+
+// CaseView is mutable view of test case passed to Transform function, changes of Name, ClassName
+// and Description are applied to transformed report. Status is provided for information only
+type CaseView struct {
+	Name        string
+	ClassName   string
+	Description string
+	Status      ExecutionStatus
+}
+
+// Transform provides new report with test cases renamed or dropped by fn, e.g. to merge parameterized
+// test names or to drop setup cases. Suite name is passed as provided by Suite. Test case is dropped when fn returns
+// false, suite counters are updated accordingly. Kept test cases keep start times of the source report
+func (report *XMLReport) Transform(fn func(suiteName string, c *CaseView) (keep bool)) *XMLReport {
+	xSuites := make([]xmlSuite, 0, len(report.xmlSuites))
+	for i, xSuite := range report.xmlSuites {
+		suiteName := report.Suite(i).Name
+		xCases := make([]xmlTest, 0, len(xSuite.Cases))
+		for j, xCase := range xSuite.Cases {
+			view := &CaseView{
+				Name:        xCase.Name,
+				ClassName:   xCase.ClassName,
+				Description: xCase.Description,
+				Status:      report.TestCaseResult(i, j).Status,
+			}
+			if !fn(suiteName, view) {
+				dropCase(&xSuite, xCase)
+				continue
+			}
+			xCase.Name = view.Name
+			xCase.ClassName = view.ClassName
+			xCase.Description = view.Description
+			xCases = append(xCases, xCase)
+		}
+		xSuite.Cases = xCases
+		xSuites = append(xSuites, xSuite)
+	}
+
+	transformed := *report
+	transformed.xmlSuites = xSuites
+	return &transformed
+}
+
+// FilterCases provides new report with test cases not matching predicate removed, e.g. to publish cases of single
+// class only. Suite name is passed as provided by Suite and test case class name is normalized as by
+// TestCaseClassName. Suites left without test cases and nested suites are dropped, suite counters are updated accordingly
+func (report *XMLReport) FilterCases(predicate func(suiteName, className, caseName string) bool) *XMLReport {
+	xSuites := make([]xmlSuite, len(report.xmlSuites))
+	keep := make([]bool, len(report.xmlSuites))
+	for i, xSuite := range report.xmlSuites {
+		suiteName := report.Suite(i).Name
+		xCases := make([]xmlTest, 0, len(xSuite.Cases))
+		for j, xCase := range xSuite.Cases {
+			if !predicate(suiteName, report.TestCaseClassName(i, j), xCase.Name) {
+				dropCase(&xSuite, xCase)
+				continue
+			}
+			xCases = append(xCases, xCase)
+		}
+		xSuite.Cases = xCases
+		xSuites[i] = xSuite
+		keep[i] = len(xCases) != 0
+	}
+	// nested suites follow their parent, so kept suite keeps its parents
+	for i := len(xSuites) - 1; i >= 0; i-- {
+		if keep[i] && xSuites[i].parent != 0 {
+			keep[xSuites[i].parent-1] = true
+		}
+	}
+
+	filtered := *report
+	filtered.xmlSuites = make([]xmlSuite, 0, len(xSuites))
+	index := make([]int, len(xSuites))
+	for i, xSuite := range xSuites {
+		if !keep[i] {
+			continue
+		}
+		if xSuite.parent != 0 {
+			xSuite.parent = index[xSuite.parent-1] + 1
+		}
+		index[i] = len(filtered.xmlSuites)
+		filtered.xmlSuites = append(filtered.xmlSuites, xSuite)
+	}
+	return &filtered
+}
+
+// dropCase updates suite counters for removed test case, test case failure is counted as suite failure
+// unless suite has no failures left
+func dropCase(xSuite *xmlSuite, xCase xmlTest) {
+	decrement := func(counter *int) {
+		if *counter > 0 {
+			*counter--
+		}
+	}
+	decrement(&xSuite.Tests)
+	if xCase.Skipped != nil {
+		decrement(&xSuite.Skipped)
+	}
+	if xCase.Failure != nil {
+		if xSuite.Failures > 0 {
+			xSuite.Failures--
+		} else {
+			decrement(&xSuite.Errors)
+		}
+	}
+	if xCase.Error != nil {
+		decrement(&xSuite.Errors)
+	}
+}
+
+// SplitByHostname partitions report top level suites with their nested suites by suite hostname attribute,
+// e.g. to publish launch per agent. Suites without hostname are kept under empty key
+func (report *XMLReport) SplitByHostname() map[string]*XMLReport {
+	xSuitesByHost := make(map[string][]xmlSuite)
+	for i := 0; i < len(report.xmlSuites); {
+		hostName := report.xmlSuites[i].HostName
+		// nested suites directly follow their top level suite
+		end := i + 1
+		for end < len(report.xmlSuites) && report.xmlSuites[end].parent != 0 {
+			end++
+		}
+
+		shift := i - len(xSuitesByHost[hostName])
+		for _, xSuite := range report.xmlSuites[i:end] {
+			if xSuite.parent != 0 {
+				xSuite.parent -= shift
+			}
+			xSuitesByHost[hostName] = append(xSuitesByHost[hostName], xSuite)
+		}
+		i = end
+	}
+
+	reports := make(map[string]*XMLReport, len(xSuitesByHost))
+	for hostName, xSuites := range xSuitesByHost {
+		split := *report
+		split.xmlSuites = xSuites
+		reports[hostName] = &split
+	}
+	return reports
+}
@@ -0,0 +1,139 @@
+package rp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransform(t *testing.T) {
+	report, err := LoadXMLReport("testdata/transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	transformed := report.Transform(func(suiteName string, c *CaseView) bool {
+		if c.Name == "setUp" || c.Status == ExecutionStatusFailed {
+			return false
+		}
+		if k := strings.IndexByte(c.Name, '['); k != -1 {
+			c.Name = c.Name[:k]
+		}
+		c.Description = suiteName
+		return true
+	})
+
+	starts := make(map[string]time.Time)
+	for i := 0; i < report.SuitesCount(); i++ {
+		for j := 0; j < report.TesCaseCount(i); j++ {
+			if _, ok := starts[report.TestCaseClassName(i, j)]; !ok {
+				starts[report.TestCaseClassName(i, j)] = report.TestCaseStartTime(i, j)
+			}
+		}
+	}
+	if n := report.TesCaseCount(0); n != 3 {
+		t.Errorf("expected source report to keep 3 cases, got %d", n)
+	}
+	stats := transformed.Stats()
+	if stats.Tests != 2 || stats.Failures != 0 || stats.Errors != 0 || stats.Skipped != 0 {
+		t.Errorf("expected 2 passed top level tests left, got %+v", stats)
+	}
+	var names []string
+	for i := 0; i < transformed.SuitesCount(); i++ {
+		for j := 0; j < transformed.TesCaseCount(i); j++ {
+			tCase := transformed.TestCase(i, j)
+			names = append(names, tCase.Description+"/"+tCase.Name)
+			// first case of every class is kept
+			if want := starts[transformed.TestCaseClassName(i, j)]; !tCase.StartTime.Equal(want) {
+				t.Errorf("case %s: expected start %s, got %s", tCase.Name, want, tCase.StartTime)
+			}
+		}
+	}
+	if got, want := strings.Join(names, " "), "t.Api/get t.Auth/login t.Ui/render"; got != want {
+		t.Errorf("expected cases %q, got %q", want, got)
+	}
+}
+
+func TestCallbacksGetDisplayedSuiteName(t *testing.T) {
+	report, err := LoadXMLReport("testdata/transform", WithSuiteNameSuffix(" (linux)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{}
+	for i := 0; i < report.SuitesCount(); i++ {
+		want[report.Suite(i).Name] = true
+	}
+	check := func(callback, suiteName string) {
+		if !want[suiteName] {
+			t.Errorf("%s: expected suite name as provided by Suite, got %q", callback, suiteName)
+		}
+	}
+
+	report.Transform(func(suiteName string, c *CaseView) bool {
+		check("Transform", suiteName)
+		return true
+	})
+	report.FilterCases(func(suiteName, className, caseName string) bool {
+		check("FilterCases", suiteName)
+		return true
+	})
+	report.CountBy(func(suiteName, className, caseName string, status ExecutionStatus) string {
+		check("CountBy", suiteName)
+		return suiteName
+	})
+}
+
+func TestFilterCases(t *testing.T) {
+	report, err := LoadXMLReport("testdata/transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered := report.FilterCases(func(suiteName, className, caseName string) bool {
+		return className != "t.Api" && caseName != "click"
+	})
+
+	var suites []string
+	for i := 0; i < filtered.SuitesCount(); i++ {
+		suites = append(suites, fmt.Sprintf("%s:%d:%d", filtered.Suite(i).Name, filtered.SuiteParent(i), filtered.TesCaseCount(i)))
+	}
+	// parent of kept nested suite is kept without its cases
+	if got, want := strings.Join(suites, " "), "t.Api:-1:0 t.Auth:0:1 t.Ui:-1:1"; got != want {
+		t.Errorf("expected suites %q, got %q", want, got)
+	}
+	stats := filtered.Stats()
+	if stats.Tests != 1 || stats.Failures != 0 || stats.Errors != 0 || stats.Skipped != 0 {
+		t.Errorf("expected 1 passed top level test left, got %+v", stats)
+	}
+
+	none := report.FilterCases(func(suiteName, className, caseName string) bool {
+		return false
+	})
+	if n := none.SuitesCount(); n != 0 {
+		t.Errorf("expected no suites left, got %d", n)
+	}
+}
+
+func TestSplitByHostname(t *testing.T) {
+	report, err := LoadXMLReport("testdata/transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports := report.SplitByHostname()
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(reports))
+	}
+
+	want := map[string]string{
+		"agent-1": "t.Api:-1 t.Auth:0",
+		"agent-2": "t.Ui:-1",
+	}
+	for hostName, split := range reports {
+		var suites []string
+		for i := 0; i < split.SuitesCount(); i++ {
+			suites = append(suites, fmt.Sprintf("%s:%d", split.Suite(i).Name, split.SuiteParent(i)))
+		}
+		if got := strings.Join(suites, " "); got != want[hostName] {
+			t.Errorf("host %q: expected suites %q, got %q", hostName, want[hostName], got)
+		}
+	}
+}
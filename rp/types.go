@@ -2,15 +2,47 @@ package rp
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 )
 
 // Client is a client for working with the RP Web API.
 type Client struct {
-	baseURL    string
-	authBearer string
-	http       *http.Client
+	baseURL       string
+	projectPrefix string
+	authBearer    string
+	http          *http.Client
+	clock         Clock
+	unfinished    *unfinishedItems
+	ordinals      *itemOrdinals
+	redactor      func(string) string
+	userAgent     string
+	typeMapper    TypeMapper
+
+	compressRequests bool
+	logLimit         *logLimiter
+	requestLogger    func(RequestInfo)
+	logRequestBody   bool
+
+	retryAttempts int
+	retryDelay    time.Duration
+	retryBackoff  *backoff
+	retryBudget   time.Duration
+}
+
+// RequestInfo describes single HTTP exchange with Report Portal, passed to WithRequestLogger logger
+type RequestInfo struct {
+	Method     string
+	URL        string
+	RequestID  string
+	Attempt    int
+	StatusCode int
+	Duration   time.Duration
+	// Err is transport error, nil when response is received regardless of its status
+	Err error
+	// Body is request body before compression, set only with WithRequestBodyLogging
+	Body []byte
 }
 
 // Launch that identifies a test run.
@@ -20,6 +52,8 @@ type Launch struct {
 	Mode        Mode      `json:"mode,omitempty"`
 	StartTime   time.Time `json:"start_time"`
 	Tags        []string  `json:"tags,omitempty"`
+	// Number is sequential launch number provided by GetLaunch, it is not sent on launch start
+	Number int `json:"-"`
 }
 
 // MarshalJSON with custom time format
@@ -42,6 +76,10 @@ type TestItem struct {
 	StartTime   time.Time    `json:"start_time"`
 	Type        TestItemType `json:"type"`
 	Tags        []string     `json:"tags,omitempty"`
+	CodeRef     string       `json:"codeRef,omitempty"`
+	// Ordinal is optional item position among its siblings starting from 1. Report Portal API v1 orders items
+	// by start time only, so item started not after its previous sibling with lower ordinal is started just after it
+	Ordinal int `json:"-"`
 }
 
 // MarshalJSON with custom time format
@@ -56,10 +94,31 @@ func (item *TestItem) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// Equal compares test items by type, name, description and start time, e.g. in tests of report mapping
+func (item *TestItem) Equal(other *TestItem) bool {
+	if item == nil || other == nil {
+		return item == other
+	}
+	return item.Type == other.Type &&
+		item.Name == other.Name &&
+		item.Description == other.Description &&
+		item.StartTime.Equal(other.StartTime)
+}
+
+// String provides readable test item representation with fields compared by Equal
+func (item *TestItem) String() string {
+	if item == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%s '%s' (%s) started at %s", item.Type, item.Name, item.Description, item.StartTime.Format(TimestampLayout))
+}
+
 // ExecutionResult is used to update executed TestItem.
 type ExecutionResult struct {
 	EndTime time.Time       `json:"end_time"`
 	Status  ExecutionStatus `json:"status"`
+	// Flaky is set by MergeResults for passed result of failed and passed attempts, it is not sent to Report Portal
+	Flaky bool `json:"-"`
 }
 
 // MarshalJSON with custom time format
@@ -74,17 +133,87 @@ func (result *ExecutionResult) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// IsPassed checks if result status is passed
+func (result *ExecutionResult) IsPassed() bool {
+	return result.Status == ExecutionStatusPassed
+}
+
+// IsFailed checks if result status is failed
+func (result *ExecutionResult) IsFailed() bool {
+	return result.Status == ExecutionStatusFailed
+}
+
+// IsSkipped checks if result status is skipped
+func (result *ExecutionResult) IsSkipped() bool {
+	return result.Status == ExecutionStatusSkipped
+}
+
+// MergeResults merges results of test reruns: merged result is failed only if every attempt failed, skipped if every
+// attempt is skipped, otherwise passed and flaky when some attempt failed. Skipped attempts are ignored otherwise.
+// End time is the latest attempt end time, nil is returned for no results
+func MergeResults(results ...*ExecutionResult) *ExecutionResult {
+	if len(results) == 0 {
+		return nil
+	}
+	var failed, passed int
+	merged := &ExecutionResult{
+		Status: ExecutionStatusSkipped,
+	}
+	for _, result := range results {
+		if result.EndTime.After(merged.EndTime) {
+			merged.EndTime = result.EndTime
+		}
+		if result.IsFailed() {
+			failed++
+		} else if !result.IsSkipped() {
+			passed++
+		}
+	}
+	switch {
+	case passed > 0:
+		merged.Status = ExecutionStatusPassed
+		merged.Flaky = failed > 0
+	case failed > 0:
+		merged.Status = ExecutionStatusFailed
+	}
+	return merged
+}
+
 // ResponceID of created item
 type ResponceID struct {
 	ID string `json:"id"`
 }
 
+// FinishResult of finished launch
+type FinishResult struct {
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	Link   string `json:"link"`
+}
+
 // LogMessage identifies test log.
 type LogMessage struct {
 	ItemID  string    `json:"item_id"`
 	Time    time.Time `json:"time"`
 	Message string    `json:"message"`
 	Level   LogLevel  `json:"level"`
+	File    *LogFile  `json:"file,omitempty"`
+	// ItemPath is dot separated path of test item message belongs to within its suite: test case full name or class
+	// name of WithParentPathName item. It is used by Publish to route message to the item, message of unknown path
+	// is sent to the test case it is published with. ItemPath is not sent to Report Portal
+	ItemPath string `json:"-"`
+}
+
+// LogFile refers log message attachment by file name
+type LogFile struct {
+	Name string `json:"name"`
+}
+
+// Attachment is file content sent with log message
+type Attachment struct {
+	Name        string
+	ContentType string
+	Content     []byte
 }
 
 // MarshalJSON with custom time format
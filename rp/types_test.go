@@ -0,0 +1,122 @@
+package rp
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMergeResults(t *testing.T) {
+	start := time.Date(2017, 5, 5, 20, 3, 50, 0, time.UTC)
+	result := func(status ExecutionStatus, end int) *ExecutionResult {
+		return &ExecutionResult{Status: status, EndTime: start.Add(time.Duration(end) * time.Second)}
+	}
+	tests := []struct {
+		name    string
+		results []*ExecutionResult
+		status  ExecutionStatus
+		flaky   bool
+		end     int
+	}{
+		{"passed", []*ExecutionResult{result(ExecutionStatusPassed, 1)}, ExecutionStatusPassed, false, 1},
+		{"passed on rerun", []*ExecutionResult{result(ExecutionStatusFailed, 1), result(ExecutionStatusPassed, 2)}, ExecutionStatusPassed, true, 2},
+		{"failed on rerun", []*ExecutionResult{result(ExecutionStatusPassed, 3), result(ExecutionStatusFailed, 2)}, ExecutionStatusPassed, true, 3},
+		{"always failed", []*ExecutionResult{result(ExecutionStatusFailed, 1), result(ExecutionStatusFailed, 2)}, ExecutionStatusFailed, false, 2},
+		{"skipped attempt ignored", []*ExecutionResult{result(ExecutionStatusSkipped, 1), result(ExecutionStatusFailed, 2)}, ExecutionStatusFailed, false, 2},
+		{"always skipped", []*ExecutionResult{result(ExecutionStatusSkipped, 1), result(ExecutionStatusSkipped, 2)}, ExecutionStatusSkipped, false, 2},
+	}
+	for _, test := range tests {
+		merged := MergeResults(test.results...)
+		if merged.Status != test.status || merged.Flaky != test.flaky {
+			t.Errorf("%s: expected %s flaky %t, got %s flaky %t", test.name, test.status, test.flaky, merged.Status, merged.Flaky)
+		}
+		if want := start.Add(time.Duration(test.end) * time.Second); !merged.EndTime.Equal(want) {
+			t.Errorf("%s: expected end time %s, got %s", test.name, want, merged.EndTime)
+		}
+	}
+	if merged := MergeResults(); merged != nil {
+		t.Errorf("expected nil for no results, got %+v", merged)
+	}
+}
+
+func TestEnumsWireValues(t *testing.T) {
+	values := map[fmt.Stringer]string{
+		TestItemTypeSuite:      "SUITE",
+		TestItemTypeStep:       "STEP",
+		TestItemTypeStory:      "STORY",
+		TestItemTypeTest:       "TEST",
+		TestItemTypeScenario:   "SCENARIO",
+		ExecutionStatusPassed:  "PASSED",
+		ExecutionStatusFailed:  "FAILED",
+		ExecutionStatusSkipped: "SKIPPED",
+		LogLevelTrace:          "TRACE",
+		LogLevelDebug:          "DEBUG",
+		LogLevelInfo:           "INFO",
+		LogLevelWarn:           "WARN",
+		LogLevelError:          "ERROR",
+		ModeDebug:              "DEBUG",
+		ModeDefault:            "DEFAULT",
+	}
+	for value, want := range values {
+		if got := fmt.Sprint(value); got != want {
+			t.Errorf("expected %T printed as %q, got %q", value, want, got)
+		}
+		text, err := value.(encoding.TextMarshaler).MarshalText()
+		if err != nil || string(text) != want {
+			t.Errorf("expected %T marshaled as %q, got %q (%v)", value, want, text, err)
+		}
+	}
+
+	// json encodes enums as wire values, map keys included
+	b, err := json.Marshal(map[ExecutionStatus]int{ExecutionStatusPassed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != `{"PASSED":1}` {
+		t.Errorf("expected status map key as wire value, got %s", got)
+	}
+}
+
+func TestExecutionResultStatusChecks(t *testing.T) {
+	for _, status := range []ExecutionStatus{ExecutionStatusPassed, ExecutionStatusFailed, ExecutionStatusSkipped, ExecutionStatus("INTERRUPTED")} {
+		result := &ExecutionResult{Status: status}
+		if result.IsPassed() != (status == ExecutionStatusPassed) {
+			t.Errorf("%s: unexpected IsPassed %t", status, result.IsPassed())
+		}
+		if result.IsFailed() != (status == ExecutionStatusFailed) {
+			t.Errorf("%s: unexpected IsFailed %t", status, result.IsFailed())
+		}
+		if result.IsSkipped() != (status == ExecutionStatusSkipped) {
+			t.Errorf("%s: unexpected IsSkipped %t", status, result.IsSkipped())
+		}
+	}
+}
+
+func TestTestItemEqual(t *testing.T) {
+	start := time.Date(2017, 5, 5, 20, 3, 50, 0, time.UTC)
+	item := &TestItem{Type: TestItemTypeStep, Name: "case", Description: "checks case", StartTime: start}
+	// fields other than type, name, description and start time are not compared, start time is compared as instant
+	equal := &TestItem{Type: TestItemTypeStep, Name: "case", Description: "checks case", StartTime: start.In(time.FixedZone("CEST", 2*60*60)),
+		LaunchID: "launch", Tags: []string{"smoke"}}
+	if !item.Equal(equal) || !equal.Equal(item) {
+		t.Errorf("expected equal items\n%s\n%s", item, equal)
+	}
+
+	differing := &TestItem{Type: TestItemTypeStep, Name: "case", Description: "checks case", StartTime: start.Add(time.Millisecond)}
+	if item.Equal(differing) || differing.Equal(item) {
+		t.Errorf("expected differing items\n%s\n%s", item, differing)
+	}
+	if item.Equal(nil) || !(*TestItem)(nil).Equal(nil) {
+		t.Error("expected nil item equal to nil only")
+	}
+
+	want := "STEP 'case' (checks case) started at 2017-05-05T20:03:50.000Z"
+	if s := item.String(); s != want {
+		t.Errorf("expected %q, got %q", want, s)
+	}
+	if s := differing.String(); s == item.String() {
+		t.Errorf("expected differing items to differ in representation, got %q", s)
+	}
+}
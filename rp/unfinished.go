@@ -0,0 +1,105 @@
+package rp
+
+import (
+	"sort"
+	"sync"
+)
+
+// unfinishedItems tracks started but not yet finished launches and test items with their start order,
+// it is safe for concurrent use
+type unfinishedItems struct {
+	mu    sync.Mutex
+	seq   int
+	items map[string]unfinishedItem
+}
+
+// unfinishedItem is start order and launch of tracked launch or test item
+type unfinishedItem struct {
+	seq      int
+	launchID string
+	launch   bool
+}
+
+func newUnfinishedItems() *unfinishedItems {
+	return &unfinishedItems{
+		items: make(map[string]unfinishedItem),
+	}
+}
+
+// start is used to track started launch or test item of given launch
+func (u *unfinishedItems) start(id, launchID string, launch bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.seq++
+	u.items[id] = unfinishedItem{seq: u.seq, launchID: launchID, launch: launch}
+}
+
+// finish is used to stop tracking finished launch or test item
+func (u *unfinishedItems) finish(id string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.items, id)
+}
+
+// unfinished provides ids of still started launches and test items of given launch, or of all launches for empty
+// launch id, children go before their parents
+func (u *unfinishedItems) unfinished(launchID string) []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	ids := make([]string, 0, len(u.items))
+	for id, item := range u.items {
+		if len(launchID) == 0 || item.launchID == launchID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(a, b int) bool {
+		return u.items[ids[a]].seq > u.items[ids[b]].seq
+	})
+	return ids
+}
+
+// isLaunch checks if tracked id is launch id
+func (u *unfinishedItems) isLaunch(id string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.items[id].launch
+}
+
+// FinishAllUnfinished finishes every launch and test item started by the client and not finished yet
+// with given status, so no item remains in progress in Report Portal after failed upload
+func (c *Client) FinishAllUnfinished(status ExecutionStatus) error {
+	return c.finishUnfinished(c.unfinished.unfinished(""), status)
+}
+
+// finishLaunchUnfinished finishes launch and its test items not finished yet with given status,
+// items of other launches started by the client are left as is
+func (c *Client) finishLaunchUnfinished(launchID string, status ExecutionStatus) error {
+	return c.finishUnfinished(c.unfinished.unfinished(launchID), status)
+}
+
+// finishUnfinished finishes tracked launches and test items with given status in given order
+func (c *Client) finishUnfinished(ids []string, status ExecutionStatus) error {
+	var err error
+	for _, id := range ids {
+		result := &ExecutionResult{
+			Status: status,
+		}
+		var finishErr error
+		if c.unfinished.isLaunch(id) {
+			_, finishErr = c.FinishLaunch(id, result)
+		} else {
+			finishErr = c.FinishTestItem(id, result)
+		}
+		if finishErr != nil {
+			err = finishErr
+		}
+		// do not retry items which could not be finished
+		c.unfinished.finish(id)
+	}
+	return err
+}
+
+// Close finishes all unfinished launches and test items as failed
+func (c *Client) Close() error {
+	return c.FinishAllUnfinished(ExecutionStatusFailed)
+}
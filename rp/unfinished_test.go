@@ -0,0 +1,100 @@
+package rp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCloseFinishesUnfinished(t *testing.T) {
+	rp := newFakeRP(t)
+	rp.failItem = func(name string) bool { return name == "broken" }
+	c := rp.client()
+
+	launchID := c.StartLaunch(&Launch{Name: "unfinished"})
+	if launchID == nil {
+		t.Fatal("could not start launch")
+	}
+	suiteID := c.StartTestItem("", &TestItem{LaunchID: launchID.ID, Name: "suite", Type: TestItemTypeSuite})
+	if suiteID == nil {
+		t.Fatal("could not start suite")
+	}
+	caseID := c.StartTestItem(suiteID.ID, &TestItem{LaunchID: launchID.ID, Name: "case", Type: TestItemTypeStep})
+	if caseID == nil {
+		t.Fatal("could not start case")
+	}
+	if c.StartTestItem(suiteID.ID, &TestItem{LaunchID: launchID.ID, Name: "broken", Type: TestItemTypeStep}) != nil {
+		t.Fatal("expected broken case start to fail")
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{caseID.ID, suiteID.ID, launchID.ID} {
+		if status, ok := rp.finishedStatus(id); !ok || status != string(ExecutionStatusFailed) {
+			t.Errorf("expected %s to be finished as failed, got finished %v with status %q", id, ok, status)
+		}
+	}
+	if ids := c.unfinished.unfinished(""); len(ids) != 0 {
+		t.Errorf("expected nothing left unfinished, got %v", ids)
+	}
+}
+
+func TestPublishFailureFinishesOwnLaunchOnly(t *testing.T) {
+	report, err := LoadXMLReport("testdata/failing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := newFakeRP(t)
+	rp.failItem = isBroken
+	c := rp.client()
+
+	// fake Report Portal starts every launch with the same id, so item of other launch is started by launch id only
+	otherSuiteID := c.StartTestItem("", &TestItem{LaunchID: "other", Name: "suite", Type: TestItemTypeSuite})
+	if otherSuiteID == nil {
+		t.Fatal("could not start suite")
+	}
+
+	if _, err := c.Publish(report, &Launch{Name: "fail fast"}, WithFailFast(true)); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, ok := rp.finishedStatus(otherSuiteID.ID); ok {
+		t.Error("expected suite of other launch to be left in progress")
+	}
+	if _, ok := rp.finishedStatus("launch"); !ok {
+		t.Error("expected published launch to be finished")
+	}
+	want := []string{otherSuiteID.ID}
+	if got := c.unfinished.unfinished(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected only other launch items %v left unfinished, got %v", want, got)
+	}
+}
+
+func TestUnfinishedOrder(t *testing.T) {
+	u := newUnfinishedItems()
+	u.start("launch", "launch", true)
+	u.start("suite", "launch", false)
+	u.start("other", "other", true)
+	u.start("case", "launch", false)
+	u.start("done", "launch", false)
+	u.finish("done")
+
+	for launchID, want := range map[string][]string{
+		"":       {"case", "other", "suite", "launch"},
+		"launch": {"case", "suite", "launch"},
+		"other":  {"other"},
+	} {
+		if got := u.unfinished(launchID); !reflect.DeepEqual(got, want) {
+			t.Errorf("launch %q: expected %v children first, got %v", launchID, want, got)
+		}
+	}
+	if !u.isLaunch("launch") || u.isLaunch("suite") {
+		t.Error("expected only launch to be tracked as launch")
+	}
+
+	for _, id := range []string{"launch", "suite", "other", "case"} {
+		u.finish(id)
+	}
+	if n := len(u.items); n != 0 {
+		t.Errorf("expected finished items not to be kept, got %d", n)
+	}
+}
@@ -1,13 +1,19 @@
 package rp
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
+	"strings"
+	"sync"
 
 	"time"
 
@@ -31,20 +37,109 @@ func InitLogger() {
 	logging.SetBackend(logger, formatter)
 }
 
-// decodeError decodes an Error from an io.Reader.
-func decodeError(r io.Reader) error {
+// APIError is Report Portal API error responce
+type APIError struct {
+	StatusCode int
+	Code       int
+	Message    string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("status: %d, code: %d, msg: %s, request id: %s", e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// PublishError holds every upload error Publish kept going after
+type PublishError struct {
+	Errors []error
+}
+
+func (e *PublishError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d upload errors: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap provides upload errors, so they could be inspected by errors.Is and errors.As
+func (e *PublishError) Unwrap() []error {
+	return e.Errors
+}
+
+// IsRetryable reports whether failed request could succeed on retry:
+// network errors, 5xx and 429 responces are retryable, any other error is permanent
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isRetryableStatus is used to check responce status code for transient server errors
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// isItemAlreadyFinished checks if err is Report Portal response to finish request of already finished item
+func isItemAlreadyFinished(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.Code == errorCodeFinishItemNotAllowed
+}
+
+// decodeError decodes an APIError from responce.
+func decodeError(resp *http.Response) error {
 	var e struct {
-		Code    int    `json:"error_code, omitempty"`
+		Code    int    `json:"error_code,omitempty"`
 		Message string `json:"message"`
 	}
-	err := json.NewDecoder(r).Decode(&e)
+	err := json.NewDecoder(resp.Body).Decode(&e)
 	if err != nil {
-		return errors.New("couldn't decode responce error")
+		e.Message = "couldn't decode responce error"
+	} else if e.Code == 0 {
+		e.Message = "no responce error"
+	}
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Code:       e.Code,
+		Message:    e.Message,
 	}
-	if e.Code == 0 {
-		return errors.New("no responce error")
+	if resp.Request != nil {
+		apiErr.RequestID = resp.Request.Header.Get(requestIDHeader)
 	}
-	return fmt.Errorf("code: %d, msg: %s", e.Code, e.Message)
+	return apiErr
+}
+
+// gzipPayload compresses request body
+func gzipPayload(payload []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	_, err := w.Write(payload)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// newRequestID generates random UUID (version 4) used to correlate request with Report Portal logs
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		log.Error(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
 }
 
 // joinURL join URL parts as path segments
@@ -58,11 +153,52 @@ func joinURL(base string, parts ...string) string {
 	return u.String()
 }
 
-// parseTimeStamp parsing with TimestampLayout
+// timestampFallbackLayouts are tried by parseTimeStamp for timestamps not matching TimestampLayout,
+// JUnit dialects write timestamps with or without fraction of second and time zone
+var timestampFallbackLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+}
+
+// parseTimeStamp parsing with TimestampLayout or one of fallback layouts, invalid timestamp is logged as zero time
 func parseTimeStamp(timeStr string) time.Time {
 	t, err := time.Parse(TimestampLayout, timeStr)
-	if err != nil {
-		log.Error(err)
+	if err == nil {
+		return t
+	}
+	for _, layout := range timestampFallbackLayouts {
+		if fallback, fallbackErr := time.Parse(layout, timeStr); fallbackErr == nil {
+			return fallback
+		}
+	}
+	log.Error(err)
+	return time.Time{}
+}
+
+// timestampCache keeps parsed timestamps, so report accessors called repeatedly while publishing do not reparse them
+type timestampCache struct {
+	mu    sync.Mutex
+	times map[string]time.Time
+}
+
+func newTimestampCache() *timestampCache {
+	return &timestampCache{
+		times: make(map[string]time.Time),
+	}
+}
+
+// timeStamp provides timestamp parsed by parseTimeStamp, each distinct timestamp is parsed once per report
+func (report *XMLReport) timeStamp(timeStr string) time.Time {
+	cache := report.timestamps
+	if cache == nil {
+		return parseTimeStamp(timeStr)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	t, ok := cache.times[timeStr]
+	if !ok {
+		t = parseTimeStamp(timeStr)
+		cache.times[timeStr] = t
 	}
 	return t
 }
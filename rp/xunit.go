@@ -0,0 +1,121 @@
+package rp
+
+import (
+	"context"
+	"encoding/xml"
+	"path"
+	"strings"
+	"time"
+)
+
+// xUnitAssemblies identifies xUnit.net v2 XML format document
+type xUnitAssemblies struct {
+	XMLName    string          `xml:"assemblies"`
+	Assemblies []xUnitAssembly `xml:"assembly"`
+}
+
+type xUnitAssembly struct {
+	Name        string            `xml:"name,attr"`
+	RunDate     string            `xml:"run-date,attr"`
+	RunTime     string            `xml:"run-time,attr"`
+	Time        xmlSeconds        `xml:"time,attr"`
+	Collections []xUnitCollection `xml:"collection"`
+}
+
+type xUnitCollection struct {
+	Name    string      `xml:"name,attr"`
+	Time    xmlSeconds  `xml:"time,attr"`
+	Total   int         `xml:"total,attr"`
+	Failed  int         `xml:"failed,attr"`
+	Skipped int         `xml:"skipped,attr"`
+	Tests   []xUnitTest `xml:"test"`
+}
+
+type xUnitTest struct {
+	Name    string        `xml:"name,attr"`
+	Type    string        `xml:"type,attr"`
+	Time    xmlSeconds    `xml:"time,attr"`
+	Result  string        `xml:"result,attr"`
+	Failure *xUnitFailure `xml:"failure"`
+	Reason  string        `xml:"reason"`
+}
+
+type xUnitFailure struct {
+	ExceptionType string `xml:"exception-type,attr"`
+	Message       string `xml:"message"`
+	StackTrace    string `xml:"stack-trace"`
+}
+
+// LoadXUnitReport is used for loading xUnit.net v2 XML report from specified directory.
+// All assemblies are reported as single launch, test collections become suites and tests become test cases
+func LoadXUnitReport(dirName string, opts ...ReportOption) (*XMLReport, error) {
+	report := newXMLReport(opts)
+	err := report.parseXMLReport(context.Background(), dirName, isXMLFile, decodeXUnitReportFile)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// decodeXUnitReportFile is used for decoding single <assemblies> document into suites
+func decodeXUnitReportFile(b []byte) (*xmlReportFile, error) {
+	var xAssemblies xUnitAssemblies
+	err := xml.Unmarshal(b, &xAssemblies)
+	if err != nil {
+		return nil, err
+	}
+
+	xSuites := make([]xmlSuite, 0)
+	for _, xAssembly := range xAssemblies.Assemblies {
+		// assembly name is path to dll, which could be Windows one
+		assemblyFile := path.Base(strings.Replace(xAssembly.Name, "\\", "/", -1))
+		packageName := strings.TrimSuffix(assemblyFile, path.Ext(assemblyFile))
+		start := parseTimeStamp(xAssembly.RunDate + "T" + xAssembly.RunTime + ".000Z")
+		for _, xCollection := range xAssembly.Collections {
+			xSuite := xUnitSuite(xCollection, packageName, start)
+			setCaseOrdinals(&xSuite)
+			xSuites = append(xSuites, xSuite)
+			start = start.Add(xCollection.Time.duration())
+		}
+	}
+	return &xmlReportFile{
+		aggregate: true,
+		suites:    xSuites,
+	}, nil
+}
+
+// xUnitSuite maps xUnit.net test collection started at given time to suite
+func xUnitSuite(xCollection xUnitCollection, packageName string, start time.Time) xmlSuite {
+	xSuite := xmlSuite{
+		Name:        xCollection.Name,
+		PackageName: packageName,
+		TimeStamp:   start.Format(TimestampLayout),
+		Time:        xCollection.Time,
+		Tests:       xCollection.Total,
+		Failures:    xCollection.Failed,
+		Skipped:     xCollection.Skipped,
+		Cases:       make([]xmlTest, 0, len(xCollection.Tests)),
+	}
+	for _, xTest := range xCollection.Tests {
+		xCase := xmlTest{
+			Name:      xTest.Name,
+			ClassName: xTest.Type,
+			Time:      xTest.Time,
+		}
+		switch xTest.Result {
+		case "Fail":
+			xCase.Failure = &xmlFailure{}
+			if xTest.Failure != nil {
+				xCase.Failure.Type = xTest.Failure.ExceptionType
+				xCase.Failure.Message = strings.TrimSpace(xTest.Failure.Message)
+				xCase.Failure.Details = strings.TrimSpace(xTest.Failure.StackTrace)
+			}
+		case "Skip":
+			xCase.Skipped = &xmlSkipped{
+				Message: strings.TrimSpace(xTest.Reason),
+			}
+		}
+		xSuite.Cases = append(xSuite.Cases, xCase)
+	}
+	return xSuite
+}
@@ -0,0 +1,48 @@
+package rp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadXUnitReport(t *testing.T) {
+	report, err := LoadXUnitReport("testdata/xunit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := report.SuitesCount(); n != 2 {
+		t.Fatalf("expected suite per collection, got %d", n)
+	}
+	stats := report.Stats()
+	if stats.Tests != 3 || stats.Failures != 1 || stats.Skipped != 1 || stats.Passed != 1 {
+		t.Errorf("expected 1 passed, 1 failed and 1 skipped test, got %+v", stats)
+	}
+
+	// collections follow each other from assembly run date
+	want := []struct {
+		name  string
+		start time.Time
+	}{
+		{"Sample.Tests.Math", time.Date(2017, 5, 5, 20, 3, 50, 0, time.UTC)},
+		{"Sample.Tests.Strings", time.Date(2017, 5, 5, 20, 3, 52, 500000000, time.UTC)},
+	}
+	for i, w := range want {
+		suite := report.Suite(i)
+		if suite.Name != w.name || !suite.StartTime.Equal(w.start) {
+			t.Errorf("suite %d: expected %s started at %s, got %s started at %s", i, w.name, w.start, suite.Name, suite.StartTime)
+		}
+	}
+
+	if failureType := report.TestCaseFailureType(0, 1); failureType != "System.DivideByZeroException" {
+		t.Errorf("expected exception type as failure type, got %q", failureType)
+	}
+	if failure := report.TestCaseFailure(0, 1); failure.Message != "Attempted to divide by zero." {
+		t.Errorf("expected exception message as failure, got %q", failure.Message)
+	}
+	if !report.HasTestCaseSkipped(1, 0) {
+		t.Fatal("expected skipped test case")
+	}
+	if skipped := report.TesCaseSkippedMessage(1, 0); skipped.Message != "not ready" {
+		t.Errorf("expected skip reason as skipped message, got %q", skipped.Message)
+	}
+}